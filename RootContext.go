@@ -1,15 +1,59 @@
 package context
 
+import (
+	"time"
+)
+
 // The RootContext interface is returned by the [NewRootContext] function.
 type RootContext interface {
 
 	// Creates new Context from your instance what implements [ContextedInstance] interface.
 	// If current root context is already in closing state it returns [ClosingIsInProcessForFreezeError] or [ClosingIsInProcessForDisposingError]
-	NewContextFor(instance ContextedInstance) (ChildContext, error)
+	NewContextFor(instance ContextedInstance, options ...Option) (ChildContext, error)
 
 	// Waits till current root context would be Closeed.
 	Wait()
 
+	// Waits till all contexts of the tree are started and ready (see [WithReadiness]).
+	// If it takes longer than timeout, it returns [ReadyTimeoutError].
+	WaitReady(timeout time.Duration) error
+
+	// Returns the first context (in creation order) that matches selector.
+	Find(selector Selector) (ChildContext, bool)
+
+	// Returns all contexts that match selector, ordered by creation.
+	FindAll(selector Selector) []ChildContext
+
+	// Returns ID, name and path of the root context
+	Info() NodeInfo
+
+	// Returns current state of the root context
+	State() State
+
+	// Returns the first error returned by tasks spawned in the root context.
+	Err() error
+
+	// Returns a snapshot of root children contexts ordered by creation.
+	Children() []ChildContext
+
+	// Root context has no parents, so it always returns empty list.
+	Parents() []ChildContext
+
+	// Returns a channel which closes when the root context reaches specified state (or any later one).
+	StateReached(state State) chan struct{}
+
+	// Returns live statistics of the tree (see [PublishExpvar]).
+	Stats() TreeStats
+
+	// Returns health of every node of the tree and the worst status of them (see [HealthChecker]).
+	Health() HealthReport
+
+	// Returns the chain of contexts which determined the total shutdown time (see [ShutdownReport]).
+	ShutdownReport() ShutdownReport
+
+	// Returns the order in which contexts would become disposing if Close() is called now, the tree is not changed (see [ShutdownPlan]).
+	ShutdownPlan() ShutdownPlan
+
 	// Close current root context and all childs according reverse order.
 	Close()
 }
@@ -17,29 +61,48 @@ type RootContext interface {
 type rootContext struct {
 	instance ContextedInstance
 	context  *context
-	done     chan struct{}
 }
 
-// NewRootContext function generates and starts new root context
-func NewRootContext(instance ContextedInstance) RootContext {
+// NewRootContext function generates and starts new root context.
+// Options are applied to the root node itself, e.g. [WithShutdownPhases] for its children, and to the whole tree, e.g. [WithOrphanPolicy].
+func NewRootContext(instance ContextedInstance, options ...Option) RootContext {
 
 	root := &rootContext{
 		instance: instance,
-		done:     make(chan struct{}),
 	}
 
 	emptyContext := newEmptyContext()
 
-	rootContext, _ := newContextFor(emptyContext, root)
+	rootOptions := newOptions(options)
+	// root context could not depend on contexts of other trees
+	rootOptions.dependencies = nil
+
+	tree := emptyContext.root
+	tree.orphans = rootOptions.orphans
+	tree.onAdopted = rootOptions.onAdopted
+	tree.observers = rootOptions.observers
+	if rootOptions.logger != nil {
+		tree.logger = rootOptions.logger
+	}
+
+	tree.ready.Lock()
+	defer tree.ready.Unlock()
+
+	rootContext, _ := newContextFor(emptyContext, root, rootOptions)
 
 	root.context = rootContext
+	tree.node = rootContext
+
+	if rootOptions.reaper != nil {
+		tree.reaper, _ = newContextFor(rootContext, rootOptions.reaper, newOptions(nil))
+	}
 
 	return root
 }
 
 // Wait ...
 func (root *rootContext) Wait() {
-	<-root.done
+	<-root.context.StateReached(Finished)
 }
 
 // Close ...
@@ -47,12 +110,92 @@ func (root *rootContext) Close() {
 	root.context.Close()
 }
 
+// Find ...
+func (root *rootContext) Find(selector Selector) (ChildContext, bool) {
+	found := root.context.root.find(selector, 1)
+	if len(found) == 0 {
+		return nil, false
+	}
+	return found[0], true
+}
+
+// FindAll ...
+func (root *rootContext) FindAll(selector Selector) []ChildContext {
+	return root.context.root.find(selector, 0)
+}
+
+// Info ...
+func (root *rootContext) Info() NodeInfo {
+	return root.context.Info()
+}
+
+// State ...
+func (root *rootContext) State() State {
+	return root.context.State()
+}
+
+// Err ...
+func (root *rootContext) Err() error {
+	return root.context.Err()
+}
+
+// Children ...
+func (root *rootContext) Children() []ChildContext {
+	return root.context.Children()
+}
+
+// Parents ...
+func (root *rootContext) Parents() []ChildContext {
+	return root.context.Parents()
+}
+
+// StateReached ...
+func (root *rootContext) StateReached(state State) chan struct{} {
+	return root.context.StateReached(state)
+}
+
+// Stats ...
+func (root *rootContext) Stats() TreeStats {
+	return root.context.root.stats()
+}
+
+// Health ...
+func (root *rootContext) Health() HealthReport {
+	return root.context.healthReport()
+}
+
+func (root *rootContext) unwrap() interface{} {
+	return root.instance
+}
+
 func (root *rootContext) Go(current Context) {
 	root.instance.Go(current)
-	close(root.done)
 }
 
 // This function uses to generate new child context from root or other child context
-func (root *rootContext) NewContextFor(instance ContextedInstance) (ChildContext, error) {
-	return root.context.NewContextFor(instance)
+func (root *rootContext) NewContextFor(instance ContextedInstance, options ...Option) (ChildContext, error) {
+	return root.context.NewContextFor(instance, options...)
+}
+
+// WaitReady ...
+func (root *rootContext) WaitReady(timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		root.context.root.ready.Lock()
+		notReady := root.context.root.notReady
+		readyChanged := root.context.root.readyChanged
+		root.context.root.ready.Unlock()
+
+		if notReady == 0 {
+			return nil
+		}
+
+		select {
+		case <-readyChanged:
+		case <-timer.C:
+			return &ReadyTimeoutError{NotReady: notReady}
+		}
+	}
 }
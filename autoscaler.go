@@ -0,0 +1,196 @@
+package context
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Signal is a metric used by autoscaler, e.g. queue length, busy workers ratio or processing time.
+type Signal interface {
+	Value() float64
+}
+
+// SignalFunc adapts an ordinary function to [Signal] interface.
+type SignalFunc func() float64
+
+// Value ...
+func (signal SignalFunc) Value() float64 {
+	return signal()
+}
+
+// ScalingRule compares one signal with two thresholds. The gap between them is a hysteresis band where pool size does not change.
+type ScalingRule struct {
+	Signal Signal
+
+	// pool grows when signal value is above this threshold
+	ScaleUpAbove float64
+
+	// pool shrinks when values of all rules are below their thresholds
+	ScaleDownBelow float64
+}
+
+// AutoscalerConfig is used by [NewAutoscaler].
+type AutoscalerConfig struct {
+	Rules []ScalingRule
+
+	// how often signals are checked, 1 second if not specified
+	Interval time.Duration
+
+	// minimal time between two scaling actions
+	Cooldown time.Duration
+
+	// number of workers added or removed at once, 1 if not specified
+	Step int
+}
+
+type autoscaler struct {
+	pool      *pool
+	config    AutoscalerConfig
+	lastScale time.Time
+}
+
+// NewAutoscaler creates an autoscaler node as a child of the pool node. It grows pool when any rule signal is above its threshold, and shrinks it when all signals are below their thresholds.
+//
+// Pool shrinks through [Pool] Scale(...), so closed workers go through the usual freeze/dispose path. Close the returned context to stop autoscaling.
+func NewAutoscaler(scaledPool Pool, config AutoscalerConfig) (ChildContext, error) {
+	pool, ok := scaledPool.(*pool)
+	if !ok {
+		return nil, &ForeignContextError{}
+	}
+
+	if config.Step <= 0 {
+		config.Step = 1
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+
+	return pool.context.NewContextFor(&autoscaler{
+		pool:   pool,
+		config: config,
+	})
+}
+
+func (autoscaler *autoscaler) Go(current Context) {
+	ticker := time.NewTicker(autoscaler.config.Interval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case now := <-ticker.C:
+			autoscaler.evaluate(now)
+		case _, isOpened := <-current.Context():
+			if !isOpened {
+				break loop
+			}
+		}
+	}
+}
+
+func (autoscaler *autoscaler) evaluate(now time.Time) {
+	if now.Sub(autoscaler.lastScale) < autoscaler.config.Cooldown {
+		return
+	}
+
+	size := autoscaler.pool.Size()
+	desired := size + autoscaler.direction()*autoscaler.config.Step
+
+	if desired < autoscaler.pool.config.Min {
+		desired = autoscaler.pool.config.Min
+	}
+	if autoscaler.pool.config.Max > 0 && desired > autoscaler.pool.config.Max {
+		desired = autoscaler.pool.config.Max
+	}
+
+	if desired != size && autoscaler.pool.Scale(desired) == nil {
+		autoscaler.lastScale = now
+	}
+}
+
+// returns 1 to grow, -1 to shrink and 0 to keep pool size
+func (autoscaler *autoscaler) direction() int {
+	if len(autoscaler.config.Rules) == 0 {
+		return 0
+	}
+
+	shrink := true
+	for _, rule := range autoscaler.config.Rules {
+		value := rule.Signal.Value()
+		if value > rule.ScaleUpAbove {
+			return 1
+		}
+		if value >= rule.ScaleDownBelow {
+			shrink = false
+		}
+	}
+
+	if shrink {
+		return -1
+	}
+
+	return 0
+}
+
+// LatencyWindow keeps last processing times and implements [Signal] as their percentile in seconds, e.g. p95.
+//
+// Workers call Observe(...) concurrently, autoscaler reads Value().
+type LatencyWindow struct {
+	percentile float64
+	samples    []time.Duration
+	next       int
+	ready      sync.Mutex
+}
+
+// NewLatencyWindow creates a window for last size samples. Percentile is in (0, 100] range, e.g. 95.
+func NewLatencyWindow(size int, percentile float64) *LatencyWindow {
+	if size < 1 {
+		size = 1
+	}
+
+	return &LatencyWindow{
+		percentile: percentile,
+		samples:    make([]time.Duration, 0, size),
+	}
+}
+
+// Observe adds a processing time, the oldest one is dropped when window is full.
+func (window *LatencyWindow) Observe(duration time.Duration) {
+	window.ready.Lock()
+	defer window.ready.Unlock()
+
+	if len(window.samples) < cap(window.samples) {
+		window.samples = append(window.samples, duration)
+		return
+	}
+
+	window.samples[window.next] = duration
+	window.next = (window.next + 1) % len(window.samples)
+}
+
+// Value returns percentile of observed processing times in seconds, 0 if there are no samples.
+func (window *LatencyWindow) Value() float64 {
+	window.ready.Lock()
+	samples := append([]time.Duration{}, window.samples...)
+	window.ready.Unlock()
+
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	// nearest-rank method
+	index := int(math.Ceil(float64(len(samples))*window.percentile/100)) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(samples) {
+		index = len(samples) - 1
+	}
+
+	return samples[index].Seconds()
+}
@@ -8,8 +8,26 @@ package context
 type ChildContext interface {
 
 	// create a new child context, for instance, what implements the instance interface
-	NewContextFor(instance ContextedInstance) (ChildContext, error)
+	NewContextFor(instance ContextedInstance, options ...Option) (ChildContext, error)
 
 	// Close current context
 	Close()
+
+	// Returns ID, name and path of the context
+	Info() NodeInfo
+
+	// Returns current state of the context
+	State() State
+
+	// Returns the first error returned by tasks spawned in the context (see Context Spawn(...) method).
+	Err() error
+
+	// Returns a snapshot of children contexts ordered by creation.
+	Children() []ChildContext
+
+	// Returns a snapshot of parent contexts ordered by creation.
+	Parents() []ChildContext
+
+	// Returns a channel which closes when the context reaches specified state (or any later one).
+	StateReached(state State) chan struct{}
 }
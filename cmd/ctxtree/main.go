@@ -0,0 +1,268 @@
+// Command ctxtree analyses dumps written by context.Journal (see README "Flight recorder").
+//
+// Usage:
+//
+//	ctxtree tree <dump>          render snapshot of the tree
+//	ctxtree replay <dump>        print journal events with time offsets
+//	ctxtree diff <old> <new>     compare snapshots of two dumps
+//	ctxtree stuck <dump>         list contexts freezed the longest and never disposed
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+const usage = `usage:
+  ctxtree tree <dump>          render snapshot of the tree
+  ctxtree replay <dump>        print journal events with time offsets
+  ctxtree diff <old> <new>     compare snapshots of two dumps
+  ctxtree stuck <dump>         list contexts freezed the longest and never disposed
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, output io.Writer) error {
+	commands := map[string]struct {
+		files   int
+		command func(output io.Writer, dumps []*context.JournalDump)
+	}{
+		"tree":   {files: 1, command: func(output io.Writer, dumps []*context.JournalDump) { renderTree(output, dumps[0]) }},
+		"replay": {files: 1, command: func(output io.Writer, dumps []*context.JournalDump) { replay(output, dumps[0]) }},
+		"diff":   {files: 2, command: func(output io.Writer, dumps []*context.JournalDump) { diff(output, dumps[0], dumps[1]) }},
+		"stuck":  {files: 1, command: func(output io.Writer, dumps []*context.JournalDump) { stuck(output, dumps[0]) }},
+	}
+
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	command, found := commands[args[0]]
+	if !found || len(args) != command.files+1 {
+		return errors.New(usage)
+	}
+
+	dumps := []*context.JournalDump{}
+	for _, path := range args[1:] {
+		dump, err := readDump(path)
+		if err != nil {
+			return err
+		}
+		dumps = append(dumps, dump)
+	}
+
+	command.command(output, dumps)
+
+	return nil
+}
+
+func readDump(path string) (*context.JournalDump, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dump, err := context.ReadDump(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	return dump, nil
+}
+
+// renders every context under its first parent, other parents only refer to it
+func renderTree(output io.Writer, dump *context.JournalDump) {
+	fmt.Fprintf(output, "dump written at %v (%v)\n", dump.Header.WrittenAt.Format(time.RFC3339Nano), dump.Header.Reason)
+	if !dump.Header.Snapshot {
+		fmt.Fprintf(output, "snapshot is missing, the tree was locked when the dump was written\n")
+	}
+
+	nodes := map[uint64]context.JournalNode{}
+	childs := map[uint64][]uint64{}
+	roots := []uint64{}
+
+	for _, node := range dump.Nodes {
+		nodes[node.ID] = node
+	}
+
+	for _, node := range dump.Nodes {
+		if len(node.Parents) == 0 {
+			roots = append(roots, node.ID)
+		}
+		for _, parent := range node.Parents {
+			if _, found := nodes[parent]; found {
+				childs[parent] = append(childs[parent], node.ID)
+			}
+		}
+	}
+
+	var render func(id uint64, parent uint64, indent string)
+	render = func(id uint64, parent uint64, indent string) {
+		node := nodes[id]
+
+		if parent != 0 && node.Parents[0] != parent {
+			fmt.Fprintf(output, "%v%v #%v (see under #%v)\n", indent, node.Name, node.ID, node.Parents[0])
+			return
+		}
+
+		fmt.Fprintf(output, "%v%v #%v %v for %v%v\n", indent, node.Name, node.ID, node.State, since(dump, node), marker(node))
+
+		for _, child := range childs[id] {
+			render(child, id, indent+"  ")
+		}
+	}
+
+	for _, id := range roots {
+		render(id, 0, "")
+	}
+}
+
+func since(dump *context.JournalDump, node context.JournalNode) time.Duration {
+	return dump.Header.WrittenAt.Sub(node.Since).Round(time.Millisecond)
+}
+
+func marker(node context.JournalNode) string {
+	if node.State == "freezed" || node.State == "disposing" {
+		return " !"
+	}
+	return ""
+}
+
+func replay(output io.Writer, dump *context.JournalDump) {
+	if dump.Header.Dropped > 0 {
+		fmt.Fprintf(output, "%v earlier events were dropped\n", dump.Header.Dropped)
+	}
+
+	if len(dump.Events) == 0 {
+		return
+	}
+
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	start := dump.Events[0].At
+
+	for _, event := range dump.Events {
+		details := ""
+		switch {
+		case event.From != "":
+			details = "from " + event.From
+		case event.Error != "":
+			details = event.Error
+		case event.Parent != nil:
+			details = "parent " + event.Parent.Path
+		}
+
+		fmt.Fprintf(writer, "+%v\t%v\t%v\t%v\n", event.At.Sub(start), event.Node.Path, event.Kind, details)
+	}
+
+	writer.Flush()
+}
+
+func diff(output io.Writer, oldDump *context.JournalDump, newDump *context.JournalDump) {
+	oldNodes := map[uint64]context.JournalNode{}
+	for _, node := range oldDump.Nodes {
+		oldNodes[node.ID] = node
+	}
+
+	newNodes := map[uint64]context.JournalNode{}
+	for _, node := range newDump.Nodes {
+		newNodes[node.ID] = node
+	}
+
+	for _, node := range oldDump.Nodes {
+		if _, found := newNodes[node.ID]; !found {
+			fmt.Fprintf(output, "- #%v %v %v\n", node.ID, node.Path, node.State)
+		}
+	}
+
+	for _, node := range newDump.Nodes {
+		oldNode, found := oldNodes[node.ID]
+		switch {
+		case !found:
+			fmt.Fprintf(output, "+ #%v %v %v\n", node.ID, node.Path, node.State)
+		case oldNode.State != node.State:
+			fmt.Fprintf(output, "~ #%v %v %v -> %v\n", node.ID, node.Path, oldNode.State, node.State)
+		case fmt.Sprint(oldNode.Parents) != fmt.Sprint(node.Parents):
+			fmt.Fprintf(output, "~ #%v %v parents %v -> %v\n", node.ID, node.Path, oldNode.Parents, node.Parents)
+		}
+	}
+}
+
+type freezedNode struct {
+	info     context.NodeInfo
+	freezed  time.Time
+	disposed time.Time
+}
+
+// collects freeze and disposing times from events, snapshot fills contexts which events were dropped
+func stuck(output io.Writer, dump *context.JournalDump) {
+	freezed := map[uint64]*freezedNode{}
+
+	for _, event := range dump.Events {
+		switch event.Kind {
+		case "freezed":
+			freezed[event.Node.ID] = &freezedNode{info: event.Node, freezed: event.At}
+		case "disposing":
+			if node, found := freezed[event.Node.ID]; found {
+				node.disposed = event.At
+			}
+		}
+	}
+
+	for _, node := range dump.Nodes {
+		if _, found := freezed[node.ID]; !found && node.State == "freezed" {
+			freezed[node.ID] = &freezedNode{info: node.NodeInfo, freezed: node.Since}
+		}
+	}
+
+	nodes := []*freezedNode{}
+	for _, node := range freezed {
+		nodes = append(nodes, node)
+	}
+
+	duration := func(node *freezedNode) time.Duration {
+		if node.disposed.IsZero() {
+			return dump.Header.WrittenAt.Sub(node.freezed)
+		}
+		return node.disposed.Sub(node.freezed)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if duration(nodes[i]) != duration(nodes[j]) {
+			return duration(nodes[i]) > duration(nodes[j])
+		}
+		return nodes[i].info.ID < nodes[j].info.ID
+	})
+
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "PATH\tFREEZED\tDISPOSED\n")
+
+	neverDisposed := []string{}
+	for _, node := range nodes {
+		disposed := "never"
+		if !node.disposed.IsZero() {
+			disposed = "yes"
+		} else {
+			neverDisposed = append(neverDisposed, node.info.Path)
+		}
+		fmt.Fprintf(writer, "%v\t%v\t%v\n", node.info.Path, duration(node).Round(time.Millisecond), disposed)
+	}
+	writer.Flush()
+
+	if len(neverDisposed) > 0 {
+		fmt.Fprintf(output, "\nnever disposed: %v\n", strings.Join(neverDisposed, ", "))
+	}
+}
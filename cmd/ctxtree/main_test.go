@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type node struct {
+	name string
+	exit chan struct{}
+}
+
+func (node *node) Name() string {
+	return node.name
+}
+
+func (node *node) Go(current context.Context) {
+	select {
+	case <-current.Context():
+	case <-node.exit:
+	}
+}
+
+func newNode(name string) *node {
+	return &node{name: name, exit: make(chan struct{})}
+}
+
+func runCommand(t *testing.T, args ...string) string {
+	output := &strings.Builder{}
+	if err := run(args, output); err != nil {
+		t.Fatal(err)
+	}
+	return output.String()
+}
+
+func expectContains(t *testing.T, output string, lines ...string) {
+	for _, line := range lines {
+		if !strings.Contains(output, line) {
+			t.Fatalf("%v not found in:\n%v", line, output)
+		}
+	}
+}
+
+func Test_Commands(t *testing.T) {
+	directory := t.TempDir()
+	journal := context.NewJournal(context.JournalConfig{})
+	defer journal.Close()
+
+	rootContext := context.NewRootContext(newNode("root"), context.WithJournal(journal))
+
+	worker := newNode("worker")
+	workerContext, err := rootContext.NewContextFor(worker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = rootContext.NewContextFor(newNode("cache")); err != nil {
+		t.Fatal(err)
+	}
+
+	before := filepath.Join(directory, "before.jsonl")
+	if err = journal.WriteFile(before); err != nil {
+		t.Fatal(err)
+	}
+
+	failingContext, err := rootContext.NewContextFor(newNode("failing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = failingContext.(context.Context).Spawn(func(stop <-chan struct{}) error {
+		return errors.New("disk is full")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-failingContext.StateReached(context.Finished)
+
+	close(worker.exit)
+	<-workerContext.StateReached(context.Finished)
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	after := filepath.Join(directory, "after.jsonl")
+	if err = journal.WriteFile(after); err != nil {
+		t.Fatal(err)
+	}
+
+	expectContains(t, runCommand(t, "tree", before), "\nroot #1 working for ", "\n  worker #2 working for ", "\n  cache #3 working for ")
+	// replay columns are aligned by the longest path, so spaces are collapsed
+	replay := strings.Join(strings.Fields(runCommand(t, "replay", after)), " ")
+	expectContains(t, replay, "root/worker exited early", "root/cache disposing from freezed", "root/failing error disk is full")
+	expectContains(t, runCommand(t, "diff", before, after), "- #1 root working\n", "- #2 root/worker working\n")
+	expectContains(t, runCommand(t, "stuck", after), "root/cache", "yes")
+
+	if err = run([]string{"diff", before}, &strings.Builder{}); err == nil {
+		t.Fatal("diff with one dump should fail")
+	}
+}
@@ -20,7 +20,12 @@
 package context
 
 import (
+	stdcontext "context"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
 	"sync"
+	"time"
 )
 
 // Instances of this interface are sent to your node through the Go() method.
@@ -29,48 +34,130 @@ import (
 type Context interface {
 
 	// creates a new child context, for instance, what implements ContextedInstance interface
-	NewContextFor(instance ContextedInstance) (ChildContext, error)
+	NewContextFor(instance ContextedInstance, options ...Option) (ChildContext, error)
 
 	// When this channel closes, it means that the child context should exit from the Go function.
 	Context() chan struct{}
 
+	// Returns current state of the context
+	State() State
+
+	// Returns a channel which closes when the context reaches specified state (or any later one).
+	StateReached(state State) chan struct{}
+
+	// Returns a snapshot of children contexts ordered by creation.
+	Children() []ChildContext
+
+	// Returns a snapshot of parent contexts ordered by creation.
+	Parents() []ChildContext
+
+	// Starts a helper task in a new goroutine. Task should return when stop channel closes (when context is freezed or its Go() method returns).
+	// Context is not disposed and not removed from its parents until all tasks have returned. The first task error closes the context (see Err()).
+	// If context is already closing, task is not started and error is returned.
+	Spawn(task func(stop <-chan struct{}) error) error
+
+	// Returns the first error returned by spawned tasks.
+	Err() error
+
+	// Creates a function-local child context. Use it with defer scope.CloseAndWait() (see [Scope]).
+	NewScope() Scope
+
+	// Signals that the node is ready and its children (and dependants) could be started. Used by nodes created with [WithReadiness] option.
+	Ready()
+
+	// Returns tree logger (see [WithLogger]) which adds name, path and current state of this context to every record.
+	Logger() Logger
+
 	// Close the current context and all children in reverse order.
 	Close()
 }
 
-type contextState int
+// State of the context.
+type State int
 
 const (
-	notStarted contextState = 0
-	working    contextState = 1
-	freezed    contextState = 2
-	disposing  contextState = 3
+	// context is waiting for its parents (or dependencies) to be ready
+	NotStarted State = 0
+	// Go() method is running
+	Working State = 1
+	// context is closing and waits for its children
+	Freezed State = 2
+	// all children are closed, Context() channel is closed and Go() method should exit
+	Disposing State = 3
+	// Go() method returned and context is removed from the tree
+	Finished State = 4
 )
 
+func (state State) String() string {
+	switch state {
+	case NotStarted:
+		return "not started"
+	case Working:
+		return "working"
+	case Freezed:
+		return "freezed"
+	case Disposing:
+		return "disposing"
+	case Finished:
+		return "finished"
+	}
+	return "unknown"
+}
+
 type context struct {
-	parents  map[*context]*context
-	childs   map[*context]*context
-	instance ContextedInstance
-	state    contextState
-	isOpened chan struct{}
-	root     *root
+	id           uint64
+	name         string
+	path         string
+	parents      map[*context]*context
+	childs       map[*context]*context
+	instance     ContextedInstance
+	options      *options
+	state        State
+	isStarted    bool
+	isReady      bool
+	isReturned   bool
+	isOpened     chan struct{}
+	reached      map[State]chan struct{}
+	at           [Finished + 1]time.Time
+	tasks        int
+	stop         chan struct{}
+	isStopped    bool
+	err          error
+	traceContext stdcontext.Context
+	lastChild    *context
+	root         *root
 }
 
 type root struct {
-	ready    sync.Mutex
-	contexts map[ContextedInstance]*context
+	ready        sync.Mutex
+	node         *context
+	reaper       *context
+	contexts     map[ContextedInstance]*context
+	sequence     uint64
+	notReady     int
+	readyChanged chan struct{}
+	orphans      OrphanPolicy
+	onAdopted    func(orphan ChildContext, adopter ChildContext)
+	callbacks    []func()
+	observers    []observer
+	logger       Logger
 }
 
 func newEmptyContext() *context {
 
 	newContext := &context{
-		parents:  map[*context]*context{},
-		childs:   map[*context]*context{},
-		instance: nil,
-		state:    working,
-		isOpened: make(chan struct{}),
+		parents:   map[*context]*context{},
+		childs:    map[*context]*context{},
+		instance:  nil,
+		options:   newOptions(nil),
+		state:     Working,
+		isStarted: true,
+		isReady:   true,
+		isOpened:  make(chan struct{}),
 		root: &root{
-			contexts: make(map[ContextedInstance]*context),
+			contexts:     make(map[ContextedInstance]*context),
+			readyChanged: make(chan struct{}),
+			logger:       nopLogger{},
 		},
 	}
 
@@ -78,83 +165,310 @@ func newEmptyContext() *context {
 }
 
 // NewContextFor ...
-func (parent *context) NewContextFor(instance ContextedInstance) (ChildContext, error) {
+func (parent *context) NewContextFor(instance ContextedInstance, options ...Option) (ChildContext, error) {
 
 	parent.root.ready.Lock()
 	defer parent.root.ready.Unlock()
 
-	switch parent.state {
-	case freezed:
-		return nil, &ClosingIsInProcessForFreezeError{}
-	case disposing:
-		return nil, &ClosingIsInProcessForDisposingError{}
+	newContext, err := parent.newChildContext(instance, newOptions(options))
+	if err != nil {
+		parent.root.emit(&event{kind: eventRejected, node: parent, err: err})
+		switch err.(type) {
+		case *ClosingIsInProcessForFreezeError, *ClosingIsInProcessForDisposingError:
+			parent.debug("new context rejected", "error", err)
+		}
+		return nil, err
 	}
 
-	return newContextFor(parent, instance)
+	return newContext, nil
 }
 
-func newContextFor(parent *context, instance ContextedInstance) (*context, error) {
+func (parent *context) newChildContext(instance ContextedInstance, options *options) (*context, error) {
+
+	if err := parent.checkIsNotClosing(); err != nil {
+		return nil, err
+	}
+
+	// instance already in the tree gets new parents, none of them could be the node itself or its descendant
+	existing := parent.root.contexts[instance]
+	if existing != nil && existing.isAncestorOf(parent) {
+		return nil, &CycleError{}
+	}
+
+	for _, dependency := range options.dependencies {
+		dependencyContext := contextOf(dependency)
+		if dependencyContext == nil || dependencyContext.root != parent.root {
+			return nil, &ForeignContextError{}
+		}
+		if err := dependencyContext.checkIsNotClosing(); err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.isAncestorOf(dependencyContext) {
+			return nil, &CycleError{}
+		}
+	}
+
+	return newContextFor(parent, instance, options)
+}
+
+func newContextFor(parent *context, instance ContextedInstance, options *options) (*context, error) {
 
 	newContext := parent.root.contexts[instance]
 
 	// if context not yet added to tree before, create new one
 	if newContext == nil {
+		parent.root.sequence++
 		newContext = &context{
+			id:       parent.root.sequence,
+			name:     nameOf(unwrap(instance)),
 			parents:  map[*context]*context{},
 			childs:   map[*context]*context{},
 			instance: instance,
-			state:    notStarted,
+			options:  options,
+			state:    NotStarted,
 			isOpened: make(chan struct{}),
 			root:     parent.root,
 		}
-
+		newContext.at[NotStarted] = time.Now()
+		newContext.path = newContext.name
+		if parent.instance != nil {
+			newContext.path = parent.path + "/" + newContext.name
+		}
+		parent.root.readinessChanged(1)
+		parent.root.emit(&event{kind: eventCreated, node: newContext, parent: parent})
 	}
 
-	newContext.parents[parent] = parent
-	parent.childs[newContext] = newContext
+	newContext.link(parent)
+	for _, dependency := range options.dependencies {
+		newContext.link(contextOf(dependency))
+	}
 	parent.root.contexts[instance] = newContext
 
-	if newContext.state == notStarted {
-		newContext.state = working
-		// Start new Context
-		go func(current *context) {
+	newContext.tryStart()
+
+	return newContext, nil
+}
+
+func contextOf(childContext ChildContext) *context {
+	switch current := childContext.(type) {
+	case *context:
+		return current
+	case *rootContext:
+		return current.context
+	}
+	return nil
+}
+
+func (current *context) checkIsNotClosing() error {
+	switch current.state {
+	case Freezed:
+		return &ClosingIsInProcessForFreezeError{}
+	case Disposing:
+		return &ClosingIsInProcessForDisposingError{}
+	case Finished:
+		return &ContextIsFinishedError{}
+	}
+	return nil
+}
+
+func (current *context) link(parent *context) {
+	current.parents[parent] = parent
+	parent.childs[current] = current
+
+	// the root context parent is an internal empty context
+	if parent.instance != nil {
+		current.root.emit(&event{kind: eventLinked, node: current, parent: parent})
+	}
+}
+
+// Reports the panic recovered by library wrappers (group members), unlike panics of ordinary nodes it does not crash the process
+func (current *context) recovered(err error) {
+	current.root.ready.Lock()
+	defer current.root.unlock()
+
+	current.root.emit(&event{kind: eventPanicked, node: current, err: err})
+}
+
+// Reports the error which closes the node (group failure)
+func (current *context) failed(err error) {
+	current.root.ready.Lock()
+	defer current.root.unlock()
+
+	current.root.emit(&event{kind: eventFailed, node: current, err: err})
+}
+
+// Removes the edge between the node and its parent without closing the node
+func (current *context) unlink(parent *context) {
+	delete(current.parents, parent)
+	delete(parent.childs, current)
+
+	current.root.emit(&event{kind: eventUnlinked, node: current, parent: parent})
+
+	// parent could wait only for this child
+	parent.freezeNextChilds()
+}
+
+// Starts the node goroutine only when all its parents (and dependencies) are ready
+func (current *context) tryStart() {
+	if current.state != NotStarted {
+		return
+	}
+
+	for parent := range current.parents {
+		if !parent.isReady {
+			return
+		}
+	}
+
+	current.setState(Working)
+	current.isStarted = true
+
+	// Start new Context
+	go current.run()
 
-			// execure user context select {...}
+	if !current.options.readiness {
+		current.setReady()
+	}
+}
+
+func (current *context) setReady() {
+	if current.isReady {
+		return
+	}
+
+	current.isReady = true
+	current.root.readinessChanged(-1)
+
+	for child := range current.childs {
+		child.tryStart()
+	}
+}
+
+func (current *context) run() {
+	isReturned := false
+
+	defer func() {
+		// Go() panicked, the panic continues after reporting
+		if !isReturned {
+			current.root.ready.Lock()
+			current.root.emit(&event{kind: eventPanicked, node: current})
+			current.root.ready.Unlock()
+		}
+	}()
+
+	// execure user context select {...} under profiler labels, so goroutine profiles show which context it is
+	pprof.Do(stdcontext.Background(), current.labels(), func(stdcontext.Context) {
+		if current.traceContext != nil {
+			trace.WithRegion(current.traceContext, "Go", func() {
+				current.instance.Go(current)
+			})
+		} else {
 			current.instance.Go(current)
+		}
+	})
+	isReturned = true
 
-			{
-				current.root.ready.Lock()
+	current.root.ready.Lock()
+	defer current.root.unlock()
 
-				if current.state != disposing {
-					// Goroutine exits without a Cancel() call, just clean it from all children. If a child has no other parents (closing last parent), initiate child closing.
-					for child := range current.childs {
-						delete(child.parents, current)
-						//fmt.Printf("[%v]\n", len(child.parents))
-						if len(child.parents) == 0 {
-							child.freezeAllChildsAndSubchilds()
-						}
-					}
-				}
-
-				// Remove node from parent childs and if parent is freezed and empty, initiate it disposing
-				delete(current.root.contexts, instance)
-				if current.parents != nil {
-					for parent := range current.parents {
-						delete(parent.childs, current)
-						if parent.state == freezed && len(parent.childs) == 0 {
-							parent.state = disposing
-							close(parent.isOpened)
-						}
-					}
-				}
+	if current.state != Disposing {
+		current.root.emit(&event{kind: eventExitedEarly, node: current})
+		current.debug("context exited early")
+	}
 
-				current.root.ready.Unlock()
+	current.isReturned = true
+	current.stopTasks()
+
+	// node is removed from the tree only when all its spawned tasks are finished
+	if current.tasks == 0 {
+		current.exit()
+	}
+}
+
+func (current *context) exit() {
+	if current.root.reaper == current {
+		current.root.reaper = nil
+	}
+
+	if current.state != Disposing {
+		// Goroutine exits without a Cancel() call, just clean it from all children. If a child has no other parents (closing last parent), initiate child closing.
+		// Not started children of a node that never became ready are closed too, because they could not start anymore.
+		for child := range current.childs {
+			delete(child.parents, current)
+			switch {
+			case !current.isReady && child.state == NotStarted:
+				child.debug("orphan freezed", "reason", "parent exited before ready")
+				child.freezeAllChildsAndSubchilds()
+			case len(child.parents) == 0:
+				child.handleOrphan(current.root.orphans)
 			}
+		}
+	}
 
-		}(newContext)
+	current.remove()
+}
+
+// Remove node from parent childs and if parent is freezed and empty, initiate it disposing
+func (current *context) remove() {
+	delete(current.root.contexts, current.instance)
+
+	if !current.isReady {
+		current.isReady = true
+		current.root.readinessChanged(-1)
 	}
 
-	return newContext, nil
+	for parent := range current.parents {
+		delete(parent.childs, current)
+		if parent.state == Freezed {
+			// the last removed child is the next step of the shutdown critical path (see ShutdownReport)
+			parent.lastChild = current
+		}
+		parent.freezeNextChilds()
+	}
+
+	current.setState(Finished)
+}
+
+func (current *context) setState(state State) {
+	from := current.state
+	current.state = state
+	current.at[state] = time.Now()
+	current.root.emit(&event{kind: eventStateChanged, node: current, from: from})
+
+	for waitingFor, reached := range current.reached {
+		if waitingFor <= state {
+			close(reached)
+			delete(current.reached, waitingFor)
+		}
+	}
+}
+
+func (current *context) dispose() {
+	current.setState(Disposing)
+	close(current.isOpened)
+
+	// node goroutine was never started, so nobody else would remove it from the tree
+	if !current.isStarted {
+		current.remove()
+	}
+}
+
+// unlocks the tree and calls user callbacks collected while it was locked
+func (root *root) unlock() {
+	callbacks := root.callbacks
+	root.callbacks = nil
+
+	root.ready.Unlock()
+
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+func (root *root) readinessChanged(delta int) {
+	root.notReady += delta
+	close(root.readyChanged)
+	root.readyChanged = make(chan struct{})
 }
 
 // Context ...
@@ -162,6 +476,176 @@ func (context *context) Context() chan struct{} {
 	return context.isOpened
 }
 
+// Info ...
+func (current *context) Info() NodeInfo {
+	return current.info()
+}
+
+// State ...
+func (current *context) State() State {
+	current.root.ready.Lock()
+	defer current.root.ready.Unlock()
+
+	return current.state
+}
+
+// StateReached ...
+func (current *context) StateReached(state State) chan struct{} {
+	current.root.ready.Lock()
+	defer current.root.ready.Unlock()
+
+	if current.reached == nil {
+		current.reached = map[State]chan struct{}{}
+	}
+
+	reached, found := current.reached[state]
+	if !found {
+		reached = make(chan struct{})
+		if current.state >= state {
+			close(reached)
+		} else {
+			current.reached[state] = reached
+		}
+	}
+
+	return reached
+}
+
+// Children ...
+func (current *context) Children() []ChildContext {
+	current.root.ready.Lock()
+	defer current.root.ready.Unlock()
+
+	return sortedHandles(current.childs)
+}
+
+// Parents ...
+func (current *context) Parents() []ChildContext {
+	current.root.ready.Lock()
+	defer current.root.ready.Unlock()
+
+	return sortedHandles(current.parents)
+}
+
+func sortedHandles(contexts map[*context]*context) []ChildContext {
+	nodes := sortedByID(contexts)
+
+	handles := make([]ChildContext, 0, len(nodes))
+	for _, node := range nodes {
+		// the root context parent is an internal empty context
+		if node.instance != nil {
+			handles = append(handles, node)
+		}
+	}
+
+	return handles
+}
+
+func sortedByID(contexts map[*context]*context) []*context {
+	nodes := make([]*context, 0, len(contexts))
+	for node := range contexts {
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+
+	return nodes
+}
+
+// Spawn ...
+func (current *context) Spawn(task func(stop <-chan struct{}) error) error {
+	current.root.ready.Lock()
+	defer current.root.ready.Unlock()
+
+	if current.isReturned {
+		return &ContextIsFinishedError{}
+	}
+
+	if err := current.checkIsNotClosing(); err != nil {
+		return err
+	}
+
+	if current.stop == nil {
+		current.stop = make(chan struct{})
+	}
+
+	current.tasks++
+
+	go func(stop chan struct{}) {
+		isReturned := false
+
+		defer func() {
+			// task panicked, the panic continues after reporting (the same as for Go() in run())
+			if !isReturned {
+				current.root.ready.Lock()
+				current.root.emit(&event{kind: eventPanicked, node: current})
+				current.root.ready.Unlock()
+			}
+		}()
+
+		pprof.Do(stdcontext.Background(), current.labels(), func(stdcontext.Context) {
+			err := task(stop)
+			isReturned = true
+			current.taskFinished(err)
+		})
+	}(current.stop)
+
+	return nil
+}
+
+func (current *context) taskFinished(err error) {
+	current.root.ready.Lock()
+	defer current.root.unlock()
+
+	current.tasks--
+
+	if err != nil && current.err == nil {
+		current.err = err
+		current.root.emit(&event{kind: eventFailed, node: current, err: err})
+		current.freezeAllChildsAndSubchilds()
+	}
+
+	if current.tasks == 0 {
+		// freezed node could wait only for its tasks
+		current.freezeNextChilds()
+
+		if current.isReturned {
+			current.exit()
+		}
+	}
+}
+
+func (current *context) labels() pprof.LabelSet {
+	return pprof.Labels(
+		"context", current.name,
+		"context_path", current.path,
+		"context_type", typeOf(unwrap(current.instance)),
+	)
+}
+
+func (current *context) stopTasks() {
+	if current.stop != nil && !current.isStopped {
+		current.isStopped = true
+		close(current.stop)
+	}
+}
+
+// Err ...
+func (current *context) Err() error {
+	current.root.ready.Lock()
+	defer current.root.ready.Unlock()
+
+	return current.err
+}
+
+// Ready ...
+func (current *context) Ready() {
+	current.root.ready.Lock()
+	defer current.root.ready.Unlock()
+
+	current.setReady()
+}
+
 // Close ...
 func (current *context) Close() {
 	current.root.ready.Lock()
@@ -172,15 +656,104 @@ func (current *context) Close() {
 
 func (current *context) freezeAllChildsAndSubchilds() {
 
-	if current.state == working {
-		current.state = freezed
-		for child := range current.childs {
-			child.freezeAllChildsAndSubchilds()
+	if current.state == Working || current.state == NotStarted {
+		current.setState(Freezed)
+		current.stopTasks()
+		current.freezeNextChilds()
+	}
+}
+
+// Freezes children of the earliest shutdown phase in reverse creation order. If there are no children left, initiates current node disposing.
+func (current *context) freezeNextChilds() {
+	if current.state != Freezed {
+		return
+	}
+
+	if len(current.childs) == 0 {
+		if current.tasks == 0 {
+			current.dispose()
+		}
+		return
+	}
+
+	for _, child := range nextChildsToFreeze(current, shutdownNodesOf(current.childs)) {
+		child.(*context).freezeAllChildsAndSubchilds()
+	}
+}
+
+// shutdownNode is a node of the live tree or of the dry-run shutdown simulation (see ShutdownPlan), so both close children by the same rules
+type shutdownNode interface {
+	// context with shutdown options and creation order of the node
+	treeContext() *context
+	currentState() State
+}
+
+func (current *context) treeContext() *context {
+	return current
+}
+
+func (current *context) currentState() State {
+	return current.state
+}
+
+func shutdownNodesOf(contexts map[*context]*context) []shutdownNode {
+	nodes := []shutdownNode{}
+	for node := range contexts {
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// Children without phase (or with phase unknown to the parent) are closed first, then named phases one by one.
+// Next phase starts only when all children of previous phases are removed from the tree.
+// Within a phase children are returned in reverse creation order. With ParallelShutdown they are all freezed in one step,
+// so only their signalling is ordered, their Go methods drain concurrently. SequentialShutdown returns them one by one.
+func nextChildsToFreeze(parent *context, childs []shutdownNode) []shutdownNode {
+	sort.Slice(childs, func(i, j int) bool { return childs[i].treeContext().id < childs[j].treeContext().id })
+
+	phase := len(parent.options.phases)
+	for _, child := range childs {
+		if childPhase := parent.phaseOf(child.treeContext()); childPhase < phase {
+			phase = childPhase
+		}
+	}
+
+	next := []shutdownNode{}
+	for i := len(childs) - 1; i >= 0; i-- {
+		if parent.phaseOf(childs[i].treeContext()) == phase {
+			next = append(next, childs[i])
+		}
+	}
+
+	if parent.options.policy == SequentialShutdown {
+		return nextForSequentialShutdown(next)
+	}
+
+	return next
+}
+
+// returns next child to freeze, or nothing if some child is still closing (the next one is freezed only when the previous one is removed)
+func nextForSequentialShutdown(childs []shutdownNode) []shutdownNode {
+	for _, child := range childs {
+		if state := child.currentState(); state == Freezed || state == Disposing {
+			return nil
+		}
+	}
+
+	for _, child := range childs {
+		if state := child.currentState(); state == Working || state == NotStarted {
+			return []shutdownNode{child}
 		}
 	}
 
-	if current.state == freezed && len(current.childs) == 0 {
-		current.state = disposing
-		close(current.isOpened)
+	return nil
+}
+
+func (current *context) phaseOf(child *context) int {
+	for i, phase := range current.options.phases {
+		if phase == child.options.phase {
+			return i + 1
+		}
 	}
+	return 0
 }
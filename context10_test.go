@@ -0,0 +1,94 @@
+package context_test
+
+import (
+	"errors"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type node10 struct {
+	name      string
+	healthErr error
+	release   chan struct{}
+}
+
+func (node *node10) Name() string {
+	return node.name
+}
+
+func (node *node10) CheckHealth() error {
+	return node.healthErr
+}
+
+func (node *node10) Go(current context.Context) {
+	<-current.Context()
+	if node.release != nil {
+		<-node.release
+	}
+}
+
+func healthOf(t *testing.T, report context.HealthReport, path string) context.HealthStatus {
+	for _, node := range report.Nodes {
+		if node.Path == path {
+			return node.Status
+		}
+	}
+	t.Fatalf("node %v not found in health report", path)
+	return context.Unhealthy
+}
+
+func Test_Health(t *testing.T) {
+	rootContext := context.NewRootContext(&node10{name: "root"})
+
+	database, err := rootContext.NewContextFor(&node10{name: "database"}, context.WithReadiness())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	server, err := rootContext.NewContextFor(&node10{name: "server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.NewContextFor(&node10{name: "handler", release: release}); err != nil {
+		t.Fatal(err)
+	}
+
+	report := rootContext.Health()
+	if report.Status != context.Starting || report.Ready() || !report.Live() {
+		t.Fatalf("unexpected tree status %v", report.Status)
+	}
+	if status := healthOf(t, report, "root/database"); status != context.Starting {
+		t.Fatalf("unexpected database status %v", status)
+	}
+	if status := healthOf(t, report, "root/server/handler"); status != context.Healthy {
+		t.Fatalf("unexpected handler status %v", status)
+	}
+
+	database.Close()
+
+	if _, err := rootContext.NewContextFor(&node10{name: "broken", healthErr: errors.New("broken")}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.Close()
+
+	report = rootContext.Health()
+	if report.Status != context.Unhealthy || report.Live() {
+		t.Fatalf("unexpected tree status %v", report.Status)
+	}
+	if status := healthOf(t, report, "root/server"); status != context.ShuttingDown {
+		t.Fatalf("unexpected server status %v", status)
+	}
+	if status := healthOf(t, report, "root/server/handler"); status != context.ShuttingDown {
+		t.Fatalf("unexpected handler status %v", status)
+	}
+	if status := healthOf(t, report, "root/broken"); status != context.Unhealthy {
+		t.Fatalf("unexpected broken status %v", status)
+	}
+
+	close(release)
+	rootContext.Close()
+	rootContext.Wait()
+}
@@ -0,0 +1,103 @@
+package context_test
+
+import (
+	"fmt"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type node11 struct {
+	name     string
+	finished chan struct{}
+}
+
+func newNode11(name string) *node11 {
+	return &node11{
+		name:     name,
+		finished: make(chan struct{}),
+	}
+}
+
+func (node *node11) Name() string {
+	return node.name
+}
+
+func (node *node11) Go(current context.Context) {
+	<-current.Context()
+	close(node.finished)
+}
+
+type otherNode11 struct{}
+
+func (node *otherNode11) Go(current context.Context) {
+	<-current.Context()
+}
+
+func Test_Find(t *testing.T) {
+	rootContext := context.NewRootContext(newNode11("root"))
+
+	poolContext, err := rootContext.NewContextFor(newNode11("pool"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workers := []*node11{}
+	for i := 0; i < 3; i++ {
+		worker := newNode11(fmt.Sprintf("worker[%v]", i))
+		workers = append(workers, worker)
+		if _, err := poolContext.NewContextFor(worker); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := rootContext.NewContextFor(&otherNode11{}); err != nil {
+		t.Fatal(err)
+	}
+
+	found := rootContext.FindAll(context.ByPath(`root/pool/worker\[*\]`))
+	if len(found) != 3 {
+		t.Fatalf("expected 3 workers, found %v", len(found))
+	}
+	for i, worker := range found {
+		if worker.Info().Name != workers[i].name {
+			t.Fatalf("unexpected worker order: %v instead of %v", worker.Info().Name, workers[i].name)
+		}
+	}
+
+	pool, ok := rootContext.Find(context.ByName("pool"))
+	if !ok || pool.Info().Path != "root/pool" {
+		t.Fatalf("pool context not found")
+	}
+
+	byID, ok := rootContext.Find(context.ByID(pool.Info().ID))
+	if !ok || byID.Info().Name != "pool" {
+		t.Fatalf("pool context not found by ID")
+	}
+
+	if len(rootContext.FindAll(context.ByType(&node11{}))) != 5 {
+		t.Fatalf("node11 contexts not found by type")
+	}
+
+	other, ok := rootContext.Find(context.ByType(&otherNode11{}))
+	if !ok || other.Info().Path != "root/*context_test.otherNode11" {
+		t.Fatalf("otherNode11 context not found by type")
+	}
+
+	if _, ok := rootContext.Find(context.ByName("unknown")); ok {
+		t.Fatalf("found context that does not exist")
+	}
+
+	found[1].Close()
+	<-workers[1].finished
+
+	if state := found[1].State(); state < context.Disposing {
+		t.Fatalf("unexpected state of closed worker: %v", state)
+	}
+	if state := found[0].State(); state != context.Working {
+		t.Fatalf("unexpected state of working worker: %v", state)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
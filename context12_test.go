@@ -0,0 +1,93 @@
+package context_test
+
+import (
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type node12 struct {
+	release chan struct{}
+	states  chan context.State
+}
+
+func (node *node12) Go(current context.Context) {
+	node.states <- current.State()
+	<-current.Context()
+	node.states <- current.State()
+	<-node.release
+}
+
+type earlyExitNode12 struct {
+	exit chan struct{}
+}
+
+func (node *earlyExitNode12) Go(current context.Context) {
+	<-node.exit
+}
+
+func Test_StateTransitions(t *testing.T) {
+	rootContext := context.NewRootContext(newNode("root"))
+
+	parent := &node12{release: make(chan struct{}), states: make(chan context.State, 2)}
+	parentContext, err := rootContext.NewContextFor(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	child := &node12{release: make(chan struct{}), states: make(chan context.State, 2)}
+	childContext, err := parentContext.NewContextFor(child)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state := <-child.states; state != context.Working {
+		t.Fatalf("unexpected child state from Go: %v", state)
+	}
+
+	parentContext.Close()
+	<-childContext.StateReached(context.Disposing)
+
+	if state := parentContext.State(); state != context.Freezed || state.String() != "freezed" {
+		t.Fatalf("unexpected parent state: %v", state)
+	}
+
+	close(child.release)
+	<-parentContext.StateReached(context.Disposing)
+	<-childContext.StateReached(context.Finished)
+
+	if state := childContext.State(); state.String() != "finished" {
+		t.Fatalf("unexpected child state: %v", state)
+	}
+
+	if _, err := childContext.NewContextFor(newNode("late")); err == nil {
+		t.Fatal("finished context accepted new child")
+	} else if _, ok := err.(*context.ContextIsFinishedError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	close(parent.release)
+	<-parentContext.StateReached(context.Finished)
+
+	early := &earlyExitNode12{exit: make(chan struct{})}
+	earlyContext, err := rootContext.NewContextFor(early)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// waiting for the skipped states also finishes
+	skipped := earlyContext.StateReached(context.Freezed)
+	close(early.exit)
+	<-skipped
+
+	if state := earlyContext.State(); state != context.Finished {
+		t.Fatalf("unexpected state after early exit: %v", state)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	if state := rootContext.State(); state != context.Finished {
+		t.Fatalf("unexpected root state after Wait: %v", state)
+	}
+}
@@ -0,0 +1,95 @@
+package context_test
+
+import (
+	"fmt"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type node13 struct {
+	name string
+	exit chan struct{}
+}
+
+func newNode13(name string) *node13 {
+	return &node13{
+		name: name,
+		exit: make(chan struct{}),
+	}
+}
+
+func (node *node13) Name() string {
+	return node.name
+}
+
+func (node *node13) Go(current context.Context) {
+	select {
+	case <-current.Context():
+	case <-node.exit:
+	}
+}
+
+func namesOf(contexts []context.ChildContext) string {
+	names := []string{}
+	for _, current := range contexts {
+		names = append(names, current.Info().Name)
+	}
+	return fmt.Sprintf("%v", names)
+}
+
+func Test_ChildrenAndParents(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	manager1, err := rootContext.NewContextFor(newNode13("manager1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager2, err := rootContext.NewContextFor(newNode13("manager2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workers := []*node13{}
+	for i := 0; i < 3; i++ {
+		worker := newNode13(fmt.Sprintf("worker%v", i))
+		workers = append(workers, worker)
+
+		if _, err := manager1.NewContextFor(worker); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sharedContext, err := manager2.NewContextFor(workers[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names := namesOf(rootContext.Children()); names != "[manager1 manager2]" {
+		t.Fatalf("unexpected root children: %v", names)
+	}
+	if len(rootContext.Parents()) != 0 {
+		t.Fatalf("root context has parents: %v", namesOf(rootContext.Parents()))
+	}
+	if names := namesOf(manager1.Children()); names != "[worker0 worker1 worker2]" {
+		t.Fatalf("unexpected manager1 children: %v", names)
+	}
+	if names := namesOf(sharedContext.Parents()); names != "[manager1 manager2]" {
+		t.Fatalf("unexpected worker1 parents: %v", names)
+	}
+
+	children := manager1.Children()
+	close(workers[0].exit)
+	<-children[0].StateReached(context.Finished)
+
+	if names := namesOf(manager1.Children()); names != "[worker1 worker2]" {
+		t.Fatalf("unexpected manager1 children after worker exit: %v", names)
+	}
+	if names := namesOf(children); names != "[worker0 worker1 worker2]" {
+		t.Fatalf("snapshot changed: %v", names)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
@@ -0,0 +1,65 @@
+package context_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+type node14 struct {
+	name            string
+	drainTime       time.Duration
+	sequenceChecker sequenceChecker
+	sequenceStep    int
+}
+
+func (node *node14) Go(current context.Context) {
+	<-current.Context()
+	time.Sleep(node.drainTime)
+	node.sequenceChecker.NotifyWithText(node.sequenceStep, "%v finished\n", node.name)
+}
+
+func Test_ShutdownPhases(t *testing.T) {
+	sequenceChecker := newSequenceChecker()
+
+	rootContext := context.NewRootContext(&node14{
+		name:            "root",
+		sequenceChecker: sequenceChecker,
+		sequenceStep:    5,
+	}, context.WithShutdownPhases("ingress", "workers", "storage"))
+
+	children := []struct {
+		name  string
+		phase string
+		step  int
+	}{
+		{name: "storage", phase: "storage", step: 4},
+		{name: "worker1", phase: "workers", step: 3},
+		{name: "worker2", phase: "workers", step: 3},
+		{name: "ingress", phase: "ingress", step: 2},
+		{name: "unphased", phase: "", step: 1},
+	}
+
+	for _, child := range children {
+		_, err := rootContext.NewContextFor(&node14{
+			name:            child.name,
+			drainTime:       10 * time.Millisecond,
+			sequenceChecker: sequenceChecker,
+			sequenceStep:    child.step,
+		}, context.InPhase(child.phase))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	if sequence := sequenceChecker.ToString(); sequence != "[1 2 3 3 4 5]" {
+		t.Fatalf("unexpected sequence: %v", sequence)
+	}
+
+	fmt.Printf("test finished with correct sequence = %v\n", sequenceChecker.ToString())
+}
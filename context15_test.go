@@ -0,0 +1,108 @@
+package context_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+func Test_SequentialShutdown(t *testing.T) {
+	sequenceChecker := newSequenceChecker()
+
+	rootContext := context.NewRootContext(&node14{
+		name:            "root",
+		sequenceChecker: sequenceChecker,
+		sequenceStep:    7,
+	}, context.WithShutdownPolicy(context.SequentialShutdown))
+
+	for i := 1; i <= 3; i++ {
+		writerContext, err := rootContext.NewContextFor(&node14{
+			name:            fmt.Sprintf("writer%v", i),
+			sequenceChecker: sequenceChecker,
+			sequenceStep:    2 * (4 - i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = writerContext.NewContextFor(&node14{
+			name:            fmt.Sprintf("writer%v->flush", i),
+			drainTime:       10 * time.Millisecond,
+			sequenceChecker: sequenceChecker,
+			sequenceStep:    2*(4-i) - 1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	if sequence := sequenceChecker.ToString(); sequence != "[1 2 3 4 5 6 7]" {
+		t.Fatalf("unexpected sequence: %v", sequence)
+	}
+
+	fmt.Printf("test finished with correct sequence = %v\n", sequenceChecker.ToString())
+}
+
+type leafWriter15 struct {
+	name     string
+	draining *int32
+	overlaps *int32
+	order    chan string
+}
+
+func (writer *leafWriter15) Name() string {
+	return writer.name
+}
+
+func (writer *leafWriter15) Go(current context.Context) {
+	<-current.Context()
+
+	if atomic.AddInt32(writer.draining, 1) > 1 {
+		atomic.AddInt32(writer.overlaps, 1)
+	}
+	time.Sleep(10 * time.Millisecond)
+	writer.order <- writer.name
+	atomic.AddInt32(writer.draining, -1)
+}
+
+func Test_SequentialShutdownOfLeafs(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"), context.WithShutdownPolicy(context.SequentialShutdown))
+
+	draining, overlaps := int32(0), int32(0)
+	order := make(chan string, 3)
+
+	for i := 1; i <= 3; i++ {
+		_, err := rootContext.NewContextFor(&leafWriter15{
+			name:     fmt.Sprintf("writer%v", i),
+			draining: &draining,
+			overlaps: &overlaps,
+			order:    order,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+	close(order)
+
+	names := []string{}
+	for name := range order {
+		names = append(names, name)
+	}
+
+	if fmt.Sprint(names) != "[writer3 writer2 writer1]" {
+		t.Fatalf("unexpected order of writers: %v", names)
+	}
+
+	if overlaps != 0 {
+		t.Fatalf("%v writers were draining at the same time", overlaps+1)
+	}
+}
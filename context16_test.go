@@ -0,0 +1,93 @@
+package context_test
+
+import (
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type worker16 struct {
+	processed chan int
+	finished  chan int
+}
+
+func (worker *worker16) Go(current context.Context) {
+	count := 0
+	for {
+		select {
+		case <-worker.processed:
+			count++
+		case <-current.Context():
+			worker.finished <- count
+			return
+		}
+	}
+}
+
+func Test_Move(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	manager1, err := rootContext.NewContextFor(newNode13("manager1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager2, err := rootContext.NewContextFor(newNode13("manager2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker := &worker16{processed: make(chan int), finished: make(chan int, 1)}
+	workerContext, err := manager1.NewContextFor(worker)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workerChild, err := workerContext.NewContextFor(newNode13("child"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker.processed <- 1
+
+	if err := context.Move(workerContext, manager2, manager1); err == nil {
+		t.Fatal("moved from parent which is not linked")
+	} else if _, ok := err.(*context.NotLinkedError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := context.Move(workerContext, manager1, workerChild); err == nil {
+		t.Fatal("moved under own child")
+	} else if _, ok := err.(*context.CycleError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := context.Move(workerContext, manager1, manager2); err != nil {
+		t.Fatal(err)
+	}
+
+	if names := namesOf(workerContext.Parents()); names != "[manager2]" {
+		t.Fatalf("unexpected worker parents: %v", names)
+	}
+
+	// worker keeps working while its previous parent is closing
+	manager1.Close()
+	<-manager1.StateReached(context.Finished)
+	worker.processed <- 2
+
+	if state := workerContext.State(); state != context.Working {
+		t.Fatalf("unexpected worker state: %v", state)
+	}
+
+	if err := context.Move(workerContext, manager2, manager1); err == nil {
+		t.Fatal("moved into finished parent")
+	}
+
+	manager2.Close()
+	if processed := <-worker.finished; processed != 2 {
+		t.Fatalf("worker lost its state, processed=%v", processed)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
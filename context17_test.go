@@ -0,0 +1,70 @@
+package context_test
+
+import (
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+func Test_Unlink(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	manager1, err := rootContext.NewContextFor(newNode13("manager1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager2, err := rootContext.NewContextFor(newNode13("manager2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shared := newNode13("shared")
+	sharedContext, err := manager1.NewContextFor(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager2.NewContextFor(shared); err != nil {
+		t.Fatal(err)
+	}
+
+	single, err := manager1.NewContextFor(newNode13("single"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := context.Unlink(manager1, sharedContext, context.FreezeOrphan); err != nil {
+		t.Fatal(err)
+	}
+	if names := namesOf(sharedContext.Parents()); names != "[manager2]" {
+		t.Fatalf("unexpected shared parents: %v", names)
+	}
+
+	if err := context.Unlink(manager1, sharedContext, context.FreezeOrphan); err == nil {
+		t.Fatal("unlinked not linked parent")
+	} else if _, ok := err.(*context.NotLinkedError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := context.Unlink(manager2, sharedContext, context.AdoptOrphanByRoot); err != nil {
+		t.Fatal(err)
+	}
+	if names := namesOf(sharedContext.Parents()); names != "[root]" {
+		t.Fatalf("orphan is not adopted by root: %v", names)
+	}
+	if state := sharedContext.State(); state != context.Working {
+		t.Fatalf("unexpected adopted orphan state: %v", state)
+	}
+
+	if err := context.Unlink(manager1, single, context.FreezeOrphan); err != nil {
+		t.Fatal(err)
+	}
+	<-single.StateReached(context.Finished)
+
+	if len(manager1.Children()) != 0 {
+		t.Fatalf("unexpected manager1 children: %v", namesOf(manager1.Children()))
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
@@ -0,0 +1,141 @@
+package context_test
+
+import (
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+type adoption18 struct {
+	orphan  string
+	adopter string
+}
+
+func Test_OrphanAdoption(t *testing.T) {
+	adoptions := make(chan adoption18, 1)
+
+	rootContext := context.NewRootContext(newNode13("root"),
+		context.WithOrphanReaper(newNode13("reaper")),
+		context.OnOrphanAdopted(func(orphan context.ChildContext, adopter context.ChildContext) {
+			adoptions <- adoption18{
+				orphan:  orphan.Info().Name,
+				adopter: adopter.Info().Name,
+			}
+		}),
+	)
+
+	parent := newNode13("parent")
+	parentContext, err := rootContext.NewContextFor(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceContext, err := parentContext.NewContextFor(newNode13("service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// parent exits without closing, so service becomes an orphan
+	close(parent.exit)
+
+	adoption := <-adoptions
+	if adoption.orphan != "service" || adoption.adopter != "reaper" {
+		t.Fatalf("unexpected adoption: %+v", adoption)
+	}
+
+	if state := serviceContext.State(); state != context.Working {
+		t.Fatalf("unexpected adopted service state: %v", state)
+	}
+	if names := namesOf(serviceContext.Parents()); names != "[reaper]" {
+		t.Fatalf("unexpected service parents: %v", names)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+func Test_OrphanFreezeByDefault(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	parent := newNode13("parent")
+	parentContext, err := rootContext.NewContextFor(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceContext, err := parentContext.NewContextFor(newNode13("service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close(parent.exit)
+	<-serviceContext.StateReached(context.Finished)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+func Test_OrphanOfExitedReaper(t *testing.T) {
+	adoptions := make(chan adoption18, 2)
+
+	reaper := newNode13("reaper")
+	rootContext := context.NewRootContext(newNode13("root"),
+		context.WithOrphanReaper(reaper),
+		context.OnOrphanAdopted(func(orphan context.ChildContext, adopter context.ChildContext) {
+			adoptions <- adoption18{
+				orphan:  orphan.Info().Name,
+				adopter: adopter.Info().Name,
+			}
+		}),
+	)
+
+	parent := newNode13("parent")
+	parentContext, err := rootContext.NewContextFor(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serviceContext, err := parentContext.NewContextFor(newNode13("service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close(parent.exit)
+	if adoption := <-adoptions; adoption.adopter != "reaper" {
+		t.Fatalf("unexpected adoption: %+v", adoption)
+	}
+
+	// reaper exits without closing, its adopted service goes to the root instead of the dying reaper
+	close(reaper.exit)
+	if adoption := <-adoptions; adoption.orphan != "service" || adoption.adopter != "root" {
+		t.Fatalf("unexpected adoption: %+v", adoption)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	if state := serviceContext.State(); state != context.Finished {
+		t.Fatalf("service is %v after the root is finished", state)
+	}
+}
+
+func Test_OrphanOfExitedRoot(t *testing.T) {
+	root := newNode13("root")
+	rootContext := context.NewRootContext(root, context.WithOrphanPolicy(context.AdoptOrphanByRoot))
+
+	serviceContext, err := rootContext.NewContextFor(newNode13("service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// root exits without closing, nobody could adopt its children, so they are freezed
+	close(root.exit)
+	rootContext.Wait()
+
+	select {
+	case <-serviceContext.StateReached(context.Finished):
+	case <-time.After(time.Second):
+		t.Fatalf("service is %v after the root is finished", serviceContext.State())
+	}
+}
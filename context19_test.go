@@ -0,0 +1,149 @@
+package context_test
+
+import (
+	"strings"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type worker19 struct {
+	index    int
+	finished chan int
+}
+
+func (worker *worker19) Go(current context.Context) {
+	<-current.Context()
+	worker.finished <- worker.index
+}
+
+func Test_Pool(t *testing.T) {
+	tracer := context.NewRecordingTracer()
+	rootContext := context.NewRootContext(newNode13("root"), context.WithTracer(tracer))
+
+	finished := make(chan int, 10)
+
+	input := newNode13("input")
+
+	pool, err := context.NewPool(rootContext, context.PoolConfig{
+		Min:   0,
+		Max:   5,
+		Input: input,
+		NewWorker: func(index int) context.ContextedInstance {
+			return &worker19{index: index, finished: finished}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pool.Scale(6); err == nil {
+		t.Fatal("pool scaled over its maximum")
+	} else if _, ok := err.(*context.PoolSizeOutOfRangeError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := pool.Scale(5); err != nil {
+		t.Fatal(err)
+	}
+
+	inputContext, ok := rootContext.Find(context.ByName("input"))
+	if !ok {
+		t.Fatal("input context not found")
+	}
+	if parents := len(inputContext.Parents()); parents != 6 {
+		t.Fatalf("input should have pool and 5 workers as parents, got %v", parents)
+	}
+
+	for size := 4; size >= 0; size-- {
+		if err := pool.Scale(size); err != nil {
+			t.Fatal(err)
+		}
+		if index := <-finished; index != size {
+			t.Fatalf("unexpected closed worker %v, expected %v", index, size)
+		}
+		if pool.Size() != size {
+			t.Fatalf("unexpected pool size %v, expected %v", pool.Size(), size)
+		}
+	}
+
+	// empty pool holds its input without any fake parent
+	if state := inputContext.State(); state != context.Working {
+		t.Fatalf("unexpected input state: %v", state)
+	}
+
+	if err := pool.Scale(2); err != nil {
+		t.Fatal(err)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	if len(finished) != 2 || inputContext.State() != context.Finished {
+		t.Fatalf("pool is not closed completely")
+	}
+
+	// scale down unlinks input from every closed worker, and observers see it
+	for _, span := range tracer.Spans() {
+		if span.Info.Name != "input" {
+			continue
+		}
+
+		unlinks := 0
+		for _, event := range span.Events {
+			if strings.HasPrefix(event, "unlinked from ") {
+				unlinks++
+			}
+		}
+
+		if unlinks != 5 {
+			t.Fatalf("input is unlinked %v times instead of 5: %v", unlinks, span.Events)
+		}
+	}
+}
+
+type idleWorker19 struct {
+	*worker19
+	idle bool
+}
+
+func (worker *idleWorker19) IsIdle() bool {
+	return worker.idle
+}
+
+func Test_PoolClosesIdleWorkersFirst(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	finished := make(chan int, 10)
+
+	pool, err := context.NewPool(rootContext, context.PoolConfig{
+		Min: 0,
+		Max: 4,
+		NewWorker: func(index int) context.ContextedInstance {
+			return &idleWorker19{
+				worker19: &worker19{index: index, finished: finished},
+				idle:     index == 1,
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pool.Scale(4); err != nil {
+		t.Fatal(err)
+	}
+
+	// idle worker goes first, then the newest busy one
+	for _, expected := range []int{1, 3} {
+		if err := pool.Scale(pool.Size() - 1); err != nil {
+			t.Fatal(err)
+		}
+		if index := <-finished; index != expected {
+			t.Fatalf("unexpected closed worker %v, expected %v", index, expected)
+		}
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
@@ -0,0 +1,124 @@
+package context_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+type queue20 struct {
+	length int
+	ready  sync.Mutex
+}
+
+func (queue *queue20) set(length int) {
+	queue.ready.Lock()
+	defer queue.ready.Unlock()
+	queue.length = length
+}
+
+func (queue *queue20) Value() float64 {
+	queue.ready.Lock()
+	defer queue.ready.Unlock()
+	return float64(queue.length)
+}
+
+func waitForPoolSize(t *testing.T, pool context.Pool, size int) {
+	deadline := time.Now().Add(time.Second)
+	for pool.Size() != size {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool size %v, expected %v", pool.Size(), size)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func Test_Autoscaler(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	pool, err := context.NewPool(rootContext, context.PoolConfig{
+		Min: 1,
+		Max: 3,
+		NewWorker: func(index int) context.ContextedInstance {
+			return newNode13("worker")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue := &queue20{}
+
+	_, err = context.NewAutoscaler(pool, context.AutoscalerConfig{
+		Rules: []context.ScalingRule{{
+			Signal:         queue,
+			ScaleUpAbove:   5,
+			ScaleDownBelow: 1,
+		}},
+		Interval: time.Millisecond,
+		Cooldown: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue.set(10)
+	waitForPoolSize(t, pool, 3)
+
+	// inside hysteresis band pool size does not change
+	queue.set(3)
+	time.Sleep(20 * time.Millisecond)
+	if size := pool.Size(); size != 3 {
+		t.Fatalf("pool size changed inside hysteresis band: %v", size)
+	}
+
+	queue.set(0)
+	waitForPoolSize(t, pool, 1)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+func Test_LatencyWindow(t *testing.T) {
+	window := context.NewLatencyWindow(20, 95)
+
+	if window.Value() != 0 {
+		t.Fatalf("empty window value %v", window.Value())
+	}
+
+	for i := 1; i <= 40; i++ {
+		window.Observe(time.Duration(i) * time.Millisecond)
+	}
+
+	// only last 20 samples (21ms..40ms) are kept, p95 of them is the 19th one
+	if value := window.Value(); value != 0.039 {
+		t.Fatalf("unexpected p95 value %v", value)
+	}
+}
+
+func Test_AutoscalerDefaultInterval(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	pool, err := context.NewPool(rootContext, context.PoolConfig{
+		Max: 2,
+		NewWorker: func(index int) context.ContextedInstance {
+			return newNode13("worker")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero interval must not panic in the autoscaler goroutine
+	autoscaler, err := context.NewAutoscaler(pool, context.AutoscalerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-autoscaler.StateReached(context.Working)
+	time.Sleep(10 * time.Millisecond)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
@@ -0,0 +1,182 @@
+package context_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+type spawningNode21 struct {
+	spawned         chan struct{}
+	sequenceChecker sequenceChecker
+}
+
+func (node *spawningNode21) Go(current context.Context) {
+	err := current.Spawn(func(stop <-chan struct{}) error {
+		<-stop
+		time.Sleep(20 * time.Millisecond)
+		node.sequenceChecker.NotifyWithText(2, "helper finished\n")
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	close(node.spawned)
+
+	<-current.Context()
+	node.sequenceChecker.NotifyWithText(3, "node finished\n")
+}
+
+func Test_SpawnDelaysDisposing(t *testing.T) {
+	sequenceChecker := newSequenceChecker()
+
+	rootContext := context.NewRootContext(&node14{
+		name:            "root",
+		sequenceChecker: sequenceChecker,
+		sequenceStep:    4,
+	})
+
+	node := &spawningNode21{spawned: make(chan struct{}), sequenceChecker: sequenceChecker}
+	nodeContext, err := rootContext.NewContextFor(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-node.spawned
+
+	sequenceChecker.NotifyWithText(1, "closing\n")
+	rootContext.Close()
+	rootContext.Wait()
+
+	if err := nodeContext.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Printf("test finished with correct sequence = %v\n", sequenceChecker.ToString())
+}
+
+type failingTaskNode21 struct {
+	err error
+}
+
+func (node *failingTaskNode21) Go(current context.Context) {
+	current.Spawn(func(stop <-chan struct{}) error {
+		return node.err
+	})
+	<-current.Context()
+}
+
+func Test_SpawnErrorClosesContext(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	node := &failingTaskNode21{err: errors.New("helper failed")}
+	nodeContext, err := rootContext.NewContextFor(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-nodeContext.StateReached(context.Finished)
+
+	if err := nodeContext.Err(); err != node.err {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+type earlyExitNode21 struct {
+	spawnErr error
+	stopped  chan struct{}
+	release  chan struct{}
+}
+
+func (node *earlyExitNode21) Go(current context.Context) {
+	current.Spawn(func(stop <-chan struct{}) error {
+		<-stop
+		node.spawnErr = current.Spawn(func(stop <-chan struct{}) error { return nil })
+		close(node.stopped)
+		<-node.release
+		return nil
+	})
+}
+
+func Test_SpawnOutlivesGo(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	node := &earlyExitNode21{stopped: make(chan struct{}), release: make(chan struct{})}
+	nodeContext, err := rootContext.NewContextFor(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Go() has returned, but node is still in the tree while its helper works
+	<-node.stopped
+	if len(rootContext.Children()) != 1 || nodeContext.State() == context.Finished {
+		t.Fatal("node removed from the tree before its helper returned")
+	}
+
+	if _, ok := node.spawnErr.(*context.ContextIsFinishedError); !ok {
+		t.Fatalf("task spawned after Go returned, error=%v", node.spawnErr)
+	}
+
+	close(node.release)
+	<-nodeContext.StateReached(context.Finished)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+// panicking task crashes the process, so the test runs itself in a child process and reads its crash dump
+func Test_SpawnPanicIsReported(t *testing.T) {
+	if directory := os.Getenv("SPAWN_PANIC_DIR"); directory != "" {
+		journal := context.NewJournal(context.JournalConfig{Dir: directory})
+		rootContext := context.NewRootContext(newNode13("root"), context.WithJournal(journal))
+
+		workerContext, err := rootContext.NewContextFor(newNode13("worker"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		workerContext.(context.Context).Spawn(func(stop <-chan struct{}) error {
+			panic("task panic")
+		})
+
+		time.Sleep(time.Second)
+		return
+	}
+
+	directory := t.TempDir()
+
+	command := exec.Command(os.Args[0], "-test.run=^Test_SpawnPanicIsReported$")
+	command.Env = append(os.Environ(), "SPAWN_PANIC_DIR="+directory)
+	if output, err := command.CombinedOutput(); err == nil {
+		t.Fatalf("process with panicking task did not crash:\n%s", output)
+	}
+
+	files, err := filepath.Glob(filepath.Join(directory, "*-crash.jsonl"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one crash dump, found %v (%v)", files, err)
+	}
+
+	file, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	dump, err := context.ReadDump(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := dump.Events[len(dump.Events)-1]
+	if last.Kind != "panicked" || last.Node.Path != "root/worker" {
+		t.Fatalf("unexpected last event %+v", last)
+	}
+}
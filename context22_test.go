@@ -0,0 +1,127 @@
+package context_test
+
+import (
+	"fmt"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type searcher22 struct {
+	results  chan string
+	name     string
+	finished chan string
+}
+
+func (searcher *searcher22) Go(current context.Context) {
+	select {
+	case searcher.results <- searcher.name:
+	case <-current.Context():
+	}
+	<-current.Context()
+	searcher.finished <- searcher.name
+}
+
+type handler22 struct {
+	requests chan int
+	handled  chan string
+	finished chan string
+}
+
+// returns the first result and leaves other searchers blocked on sending
+func (handler *handler22) handle(current context.Context, request int) string {
+	scope := current.NewScope()
+	defer scope.CloseAndWait()
+
+	results := make(chan string)
+	for i := 0; i < 3; i++ {
+		_, err := scope.NewContextFor(&searcher22{
+			results:  results,
+			name:     fmt.Sprintf("request%v/searcher%v", request, i),
+			finished: handler.finished,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return <-results
+}
+
+func (handler *handler22) Go(current context.Context) {
+	for {
+		select {
+		case request := <-handler.requests:
+			handler.handled <- handler.handle(current, request)
+		case <-current.Context():
+			return
+		}
+	}
+}
+
+func Test_Scope(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	handler := &handler22{
+		requests: make(chan int),
+		handled:  make(chan string),
+		finished: make(chan string, 3),
+	}
+
+	handlerContext, err := rootContext.NewContextFor(handler)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for request := 0; request < 3; request++ {
+		handler.requests <- request
+		fmt.Printf("handled: %v\n", <-handler.handled)
+
+		// all scope children are finished when handler responds
+		if len(handler.finished) != 3 {
+			t.Fatalf("scope children are not finished, finished=%v", len(handler.finished))
+		}
+		for i := 0; i < 3; i++ {
+			<-handler.finished
+		}
+
+		if children := handlerContext.Children(); len(children) != 0 {
+			t.Fatalf("scope is not removed from the tree: %v", namesOf(children))
+		}
+	}
+
+	handlerContext.Close()
+	<-handlerContext.StateReached(context.Finished)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+type closedParent22 struct {
+	err chan error
+}
+
+func (node *closedParent22) Go(current context.Context) {
+	current.Close()
+	scope := current.NewScope()
+	defer scope.CloseAndWait()
+
+	_, err := scope.NewContextFor(newNode13("child"))
+	node.err <- err
+}
+
+func Test_ScopeOfClosingContext(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	node := &closedParent22{err: make(chan error, 1)}
+	if _, err := rootContext.NewContextFor(node); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := (<-node.err).(*context.ClosingIsInProcessForDisposingError); !ok {
+		t.Fatal("scope of closing context accepted new child")
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
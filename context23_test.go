@@ -0,0 +1,109 @@
+package context_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+func Test_Metrics(t *testing.T) {
+	metrics := context.NewMetrics()
+
+	rootContext := context.NewRootContext(newNode13("root"), context.WithMetrics(metrics))
+
+	early := newNode13("worker")
+	earlyContext, err := rootContext.NewContextFor(early)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rootContext.NewContextFor(newNode13("worker")); err != nil {
+		t.Fatal(err)
+	}
+
+	close(early.exit)
+	<-earlyContext.StateReached(context.Finished)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/openmetrics-text") {
+		t.Fatalf("unexpected content type: %v", contentType)
+	}
+
+	expectLines(t, recorder.Body.String(),
+		`context_created_total{name="worker",type="*context_test.node13"} 2`,
+		`context_exited_early_total{name="worker",type="*context_test.node13"} 1`,
+		`context_state{name="worker",type="*context_test.node13",state="working"} 1`,
+		`context_state{name="root",type="*context_test.node13",state="working"} 1`,
+		`context_lifetime_seconds_count{name="worker",type="*context_test.node13"} 1`,
+	)
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	output := &strings.Builder{}
+	if _, err := metrics.WriteTo(output); err != nil {
+		t.Fatal(err)
+	}
+
+	expectLines(t, output.String(),
+		`context_frozen_total{name="root",type="*context_test.node13"} 1`,
+		`context_disposed_total{name="worker",type="*context_test.node13"} 1`,
+		`context_drain_seconds_bucket{name="worker",type="*context_test.node13",le="+Inf"} 1`,
+		`context_drain_seconds_count{name="root",type="*context_test.node13"} 1`,
+		`context_lifetime_seconds_count{name="worker",type="*context_test.node13"} 2`,
+		`# EOF`,
+	)
+
+	if strings.Contains(output.String(), "context_state{") {
+		t.Fatalf("finished contexts are still counted:\n%v", output.String())
+	}
+}
+
+func expectLines(t *testing.T, output string, lines ...string) {
+	for _, line := range lines {
+		if !strings.Contains(output, line+"\n") {
+			t.Fatalf("line %v not found in:\n%v", line, output)
+		}
+	}
+}
+
+type panicMember23 struct{}
+
+func (member *panicMember23) Name() string {
+	return "member"
+}
+
+func (member *panicMember23) Go(current context.Context) error {
+	panic("member panic")
+}
+
+func Test_MetricsOfRecoveredPanic(t *testing.T) {
+	metrics := context.NewMetrics()
+
+	rootContext := context.NewRootContext(newNode13("root"), context.WithMetrics(metrics))
+
+	group, err := context.NewGroup(rootContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := group.NewContextFor(&panicMember23{}); err != nil {
+		t.Fatal(err)
+	}
+
+	<-group.Done()
+
+	output := &strings.Builder{}
+	if _, err := metrics.WriteTo(output); err != nil {
+		t.Fatal(err)
+	}
+
+	expectLines(t, output.String(), `context_panicked_total{name="member",type="*context_test.panicMember23"} 1`)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
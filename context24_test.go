@@ -0,0 +1,70 @@
+package context_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+func Test_ExpvarStats(t *testing.T) {
+	// expvar names are global, so every test run uses its own ones
+	name1 := fmt.Sprintf("%v_root1_%v", t.Name(), time.Now().UnixNano())
+	name2 := fmt.Sprintf("%v_root2_%v", t.Name(), time.Now().UnixNano())
+
+	rootContext1 := context.NewRootContext(newNode13("root1"))
+	rootContext2 := context.NewRootContext(newNode13("root2"))
+
+	if err := context.PublishExpvar(name1, rootContext1); err != nil {
+		t.Fatal(err)
+	}
+	if err := context.PublishExpvar(name2, rootContext2); err != nil {
+		t.Fatal(err)
+	}
+	if err := context.PublishExpvar(name1, rootContext2); err == nil {
+		t.Fatal("published twice with the same name")
+	} else if _, ok := err.(*context.ExpvarIsAlreadyPublishedError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parentContext, err := rootContext1.NewContextFor(newNode13("parent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	if _, err := parentContext.NewContextFor(&node10{name: "child", release: release}); err != nil {
+		t.Fatal(err)
+	}
+
+	parentContext.Close()
+
+	stats := context.TreeStats{}
+	if err := json.Unmarshal([]byte(expvar.Get(name1).String()), &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Total != 3 || stats.MaxDepth != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.States["working"] != 1 || stats.States["freezed"] != 1 || stats.States["disposing"] != 1 {
+		t.Fatalf("unexpected states: %v", stats.States)
+	}
+	if stats.OldestFreezed == nil || stats.OldestFreezed.Path != "root1/parent" {
+		t.Fatalf("unexpected oldest freezed node: %+v", stats.OldestFreezed)
+	}
+
+	if stats := rootContext2.Stats(); stats.Total != 1 || stats.MaxDepth != 1 || stats.OldestFreezed != nil {
+		t.Fatalf("unexpected root2 stats: %+v", stats)
+	}
+
+	close(release)
+
+	rootContext1.Close()
+	rootContext1.Wait()
+	rootContext2.Close()
+	rootContext2.Wait()
+}
@@ -0,0 +1,50 @@
+package context_test
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type labelledNode25 struct {
+	started chan struct{}
+}
+
+func (node *labelledNode25) Name() string {
+	return "labelled"
+}
+
+func (node *labelledNode25) Go(current context.Context) {
+	current.Spawn(func(stop <-chan struct{}) error {
+		<-stop
+		return nil
+	})
+	close(node.started)
+	<-current.Context()
+}
+
+func Test_ProfilerLabels(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	node := &labelledNode25{started: make(chan struct{})}
+	if _, err := rootContext.NewContextFor(node); err != nil {
+		t.Fatal(err)
+	}
+	<-node.started
+
+	profile := &bytes.Buffer{}
+	if err := pprof.Lookup("goroutine").WriteTo(profile, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	labels := `"context":"labelled", "context_path":"root/labelled", "context_type":"*context_test.labelledNode25"`
+	if count := strings.Count(profile.String(), labels); count != 2 {
+		t.Fatalf("expected node and its task goroutines with labels %v, found %v in:\n%v", labels, count, profile.String())
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
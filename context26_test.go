@@ -0,0 +1,37 @@
+package context_test
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+func Test_RuntimeTrace(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := trace.Start(buffer); err != nil {
+		t.Skipf("runtime tracing is already in use: %v", err)
+	}
+
+	rootContext := context.NewRootContext(newNode13("root"), context.WithRuntimeTrace())
+
+	parent, err := rootContext.NewContextFor(newNode13("parent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = parent.NewContextFor(newNode13("child")); err != nil {
+		t.Fatal(err)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+	trace.Stop()
+
+	for _, name := range []string{"context root", "context root/parent", "context root/parent/child", "working", "freezed", "disposing", "Go"} {
+		if !bytes.Contains(buffer.Bytes(), []byte(name)) {
+			t.Fatalf("trace does not contain %v", name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package context_test
+
+import (
+	"strings"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+func Test_Tracer(t *testing.T) {
+	tracer := context.NewRecordingTracer()
+	rootContext := context.NewRootContext(newNode13("root"), context.WithTracer(tracer))
+
+	first, err := rootContext.NewContextFor(newNode13("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := rootContext.NewContextFor(newNode13("second"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = first.NewContextFor(newNode13("shared"), context.DependsOn(second)); err != nil {
+		t.Fatal(err)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	spans := tracer.Spans()
+
+	expected := map[string]string{
+		"root":   "",
+		"first":  "root",
+		"second": "root",
+		"shared": "first,second",
+	}
+
+	if len(spans) != len(expected) {
+		t.Fatalf("expected %v spans, but recorded %v", len(expected), len(spans))
+	}
+
+	for _, span := range spans {
+		parents := []string{}
+		for _, parent := range span.Parents {
+			parents = append(parents, parent.Name)
+		}
+
+		if strings.Join(parents, ",") != expected[span.Info.Name] {
+			t.Fatalf("span %v has parents %v, expected %v", span.Info.Name, parents, expected[span.Info.Name])
+		}
+
+		if events := strings.Join(span.Events, ","); events != "working,freezed,disposing" {
+			t.Fatalf("span %v has events %v", span.Info.Name, events)
+		}
+
+		if !span.Ended {
+			t.Fatalf("span %v is not ended", span.Info.Name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package context_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+type logger28 struct {
+	records []string
+	ready   sync.Mutex
+}
+
+func (logger *logger28) log(level string, msg string, args ...interface{}) {
+	logger.ready.Lock()
+	defer logger.ready.Unlock()
+	logger.records = append(logger.records, fmt.Sprintf("%v %v %v", level, msg, args))
+}
+
+func (logger *logger28) Debug(msg string, args ...interface{}) { logger.log("DEBUG", msg, args...) }
+func (logger *logger28) Info(msg string, args ...interface{})  { logger.log("INFO", msg, args...) }
+func (logger *logger28) Warn(msg string, args ...interface{})  { logger.log("WARN", msg, args...) }
+func (logger *logger28) Error(msg string, args ...interface{}) { logger.log("ERROR", msg, args...) }
+
+func (logger *logger28) expect(t *testing.T, prefix string) {
+	logger.ready.Lock()
+	defer logger.ready.Unlock()
+
+	for _, current := range logger.records {
+		if strings.HasPrefix(current, prefix) {
+			return
+		}
+	}
+	t.Fatalf("record %v not found in:\n%v", prefix, strings.Join(logger.records, "\n"))
+}
+
+type node28 struct {
+	logged chan struct{}
+}
+
+func (node *node28) Name() string {
+	return "worker"
+}
+
+func (node *node28) Go(current context.Context) {
+	current.Logger().Info("started", "attempt", 1)
+	close(node.logged)
+	<-current.Context()
+}
+
+func Test_Logger(t *testing.T) {
+	logger := &logger28{}
+	rootContext := context.NewRootContext(newNode13("root"), context.WithLogger(logger))
+
+	worker := &node28{logged: make(chan struct{})}
+	if _, err := rootContext.NewContextFor(worker); err != nil {
+		t.Fatal(err)
+	}
+	<-worker.logged
+
+	logger.expect(t, "INFO started [context worker path root/worker state working attempt 1]")
+
+	early := newNode13("early")
+	earlyContext, err := rootContext.NewContextFor(early)
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(early.exit)
+	<-earlyContext.StateReached(context.Finished)
+
+	logger.expect(t, "DEBUG context exited early [context early path root/early state working]")
+
+	rootContext.Close()
+	if _, err := rootContext.NewContextFor(newNode13("late")); err == nil {
+		t.Fatal("context created after Close")
+	}
+	rootContext.Wait()
+
+	// root could be already disposing, so only the beginning of the record is checked
+	logger.expect(t, "DEBUG new context rejected [context root path root state ")
+}
@@ -0,0 +1,67 @@
+package context_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+type node29 struct {
+	name      string
+	drainTime time.Duration
+}
+
+func (node *node29) Name() string {
+	return node.name
+}
+
+func (node *node29) Go(current context.Context) {
+	<-current.Context()
+	time.Sleep(node.drainTime)
+}
+
+func Test_ShutdownReport(t *testing.T) {
+	rootContext := context.NewRootContext(&node29{name: "root"})
+
+	service, err := rootContext.NewContextFor(&node29{name: "service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = service.NewContextFor(&node29{name: "database", drainTime: 100 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = rootContext.NewContextFor(&node29{name: "cache", drainTime: 10 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	report := rootContext.ShutdownReport()
+
+	paths := []string{}
+	for _, step := range report.Steps {
+		paths = append(paths, step.Path)
+	}
+
+	if strings.Join(paths, ",") != "root,root/service,root/service/database" {
+		t.Fatalf("unexpected critical path %v\n%v", paths, report)
+	}
+
+	database := report.Steps[2]
+	if database.Exit < 100*time.Millisecond {
+		t.Fatalf("database exit took %v, expected at least 100ms", database.Exit)
+	}
+
+	if report.Steps[0].Drain < database.Exit || report.Total < report.Steps[0].Drain {
+		t.Fatalf("critical path durations are inconsistent:\n%v", report)
+	}
+
+	if !strings.Contains(report.String(), "root/service/database") {
+		t.Fatalf("report does not contain critical step:\n%v", report)
+	}
+}
@@ -0,0 +1,126 @@
+package context_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	context "github.com/mcfly722/context"
+)
+
+func Test_ShutdownPlan(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"), context.WithShutdownPhases("workers", "storage"))
+
+	if _, err := rootContext.NewContextFor(newNode13("database"), context.InPhase("storage")); err != nil {
+		t.Fatal(err)
+	}
+
+	worker1, err := rootContext.NewContextFor(newNode13("worker1"), context.InPhase("workers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker2, err := rootContext.NewContextFor(newNode13("worker2"), context.InPhase("workers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = worker1.NewContextFor(newNode13("job"), context.DependsOn(worker2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = rootContext.NewContextFor(newNode13("queue"), context.WithShutdownPolicy(context.SequentialShutdown)); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := rootContext.ShutdownPlan()
+
+	// unphased queue is closed first, then workers phase, where both workers wait for their shared job
+	expected := "" +
+		"round 1: root/queue\n" +
+		"round 2: root/worker1/job\n" +
+		"round 3: root/worker1, root/worker2\n" +
+		"round 4: root/database\n" +
+		"round 5: root\n"
+
+	if plan.String() != expected {
+		t.Fatalf("unexpected plan:\n%v\nexpected:\n%v", plan, expected)
+	}
+
+	encoded, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(encoded), `{"rounds":[[{"id":`) || !strings.Contains(string(encoded), `"path":"root/worker1/job"`) {
+		t.Fatalf("unexpected json: %v", string(encoded))
+	}
+
+	if state := rootContext.State(); state != context.Working {
+		t.Fatalf("dry run changed root state to %v", state)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+func Test_ShutdownPlanOfSequentialLeafs(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"), context.WithShutdownPolicy(context.SequentialShutdown))
+
+	for _, name := range []string{"writer1", "writer2", "writer3"} {
+		if _, err := rootContext.NewContextFor(newNode13(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// plan uses the same ordering rules as the live tree, so every writer waits for the previous one
+	expected := "" +
+		"round 1: root/writer3\n" +
+		"round 2: root/writer2\n" +
+		"round 3: root/writer1\n" +
+		"round 4: root\n"
+
+	if plan := rootContext.ShutdownPlan(); plan.String() != expected {
+		t.Fatalf("unexpected plan:\n%v\nexpected:\n%v", plan, expected)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+func Test_ShutdownPlanOfFreezedSpawner(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	spawnerContext, err := rootContext.NewContextFor(newNode13("spawner"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = rootContext.NewContextFor(newNode13("other")); err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	if err = spawnerContext.(context.Context).Spawn(func(stop <-chan struct{}) error {
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// spawner is freezed, but waits for its task
+	spawnerContext.Close()
+
+	expected := "" +
+		"round 1: root/spawner, root/other\n" +
+		"round 2: root\n"
+
+	if plan := rootContext.ShutdownPlan(); plan.String() != expected {
+		t.Fatalf("unexpected plan:\n%v\nexpected:\n%v", plan, expected)
+	}
+
+	close(release)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
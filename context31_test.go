@@ -0,0 +1,174 @@
+package context_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+func Test_JournalWriteTo(t *testing.T) {
+	journal := context.NewJournal(context.JournalConfig{Size: 4})
+	defer journal.Close()
+
+	rootContext := context.NewRootContext(newNode13("root"), context.WithJournal(journal))
+
+	for _, name := range []string{"first", "second"} {
+		if _, err := rootContext.NewContextFor(newNode13(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	if _, err := journal.WriteTo(buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	dump, err := context.ReadDump(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dump.Header.Version != context.JournalVersion || dump.Header.Reason != context.DumpOnDemand || dump.Header.Dropped == 0 {
+		t.Fatalf("unexpected header %+v", dump.Header)
+	}
+
+	if len(dump.Events) != 4 {
+		t.Fatalf("ring buffer keeps %v events instead of 4", len(dump.Events))
+	}
+
+	last := dump.Events[len(dump.Events)-1]
+	if last.Node.Path != "root/second" || last.Kind != "working" || last.From != "not started" {
+		t.Fatalf("unexpected last event %+v", last)
+	}
+
+	paths := []string{}
+	for _, node := range dump.Nodes {
+		paths = append(paths, node.Path+" "+node.State)
+	}
+
+	if strings.Join(paths, ",") != "root working,root/first working,root/second working" {
+		t.Fatalf("unexpected snapshot %v", paths)
+	}
+}
+
+func Test_JournalDumpOnStall(t *testing.T) {
+	directory := t.TempDir()
+
+	journal := context.NewJournal(context.JournalConfig{
+		Dir:          directory,
+		StallTimeout: 20 * time.Millisecond,
+	})
+	defer journal.Close()
+
+	rootContext := context.NewRootContext(newNode13("root"), context.WithJournal(journal))
+
+	if _, err := rootContext.NewContextFor(&node29{name: "slow", drainTime: 200 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	files, err := filepath.Glob(filepath.Join(directory, "*-stall.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("expected one stall dump, found %v", files)
+	}
+
+	file, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	dump, err := context.ReadDump(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	states := map[string]string{}
+	for _, node := range dump.Nodes {
+		states[node.Path] = node.State
+	}
+
+	if dump.Header.Reason != context.DumpOnStall || states["root"] != "freezed" || states["root/slow"] != "disposing" {
+		t.Fatalf("unexpected stall dump %+v with states %v", dump.Header, states)
+	}
+}
+
+func Test_ReadDumpVersion(t *testing.T) {
+	_, err := context.ReadDump(strings.NewReader(`{"header":{"version":2}}`))
+	if _, ok := err.(*context.JournalVersionError); !ok {
+		t.Fatalf("expected JournalVersionError, got %v", err)
+	}
+}
+
+type failingMember31 struct{}
+
+func (member *failingMember31) Go(current context.Context) error {
+	return errors.New("member failed")
+}
+
+func Test_JournalErrors(t *testing.T) {
+	journal := context.NewJournal(context.JournalConfig{})
+	defer journal.Close()
+
+	rootContext := context.NewRootContext(newNode13("root"), context.WithJournal(journal))
+
+	workerContext, err := rootContext.NewContextFor(newNode13("worker"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = workerContext.(context.Context).Spawn(func(stop <-chan struct{}) error {
+		return errors.New("task failed")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-workerContext.StateReached(context.Finished)
+
+	group, err := context.NewGroup(rootContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = group.NewContextFor(&failingMember31{}); err != nil {
+		t.Fatal(err)
+	}
+	<-group.Done()
+
+	buffer := &bytes.Buffer{}
+	if _, err := journal.WriteTo(buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	dump, err := context.ReadDump(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failures := []string{}
+	for _, event := range dump.Events {
+		if event.Kind == "error" {
+			failures = append(failures, event.Node.Path+": "+event.Error)
+		}
+	}
+
+	if strings.Join(failures, ", ") != "root/worker: task failed, root/*context.group: member failed" {
+		t.Fatalf("unexpected error events: %v", failures)
+	}
+}
@@ -0,0 +1,123 @@
+package context_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+type groupMember8 struct {
+	name            string
+	fail            chan error
+	sequenceChecker sequenceChecker
+	sequenceStep    int
+}
+
+func (member *groupMember8) Go(current context.Context) error {
+	for {
+		select {
+		case err := <-member.fail:
+			member.sequenceChecker.NotifyWithText(2, "%v failed\n", member.name)
+			return err
+		case _, isOpened := <-current.Context():
+			if !isOpened {
+				member.sequenceChecker.NotifyWithText(member.sequenceStep, "%v finished\n", member.name)
+				return nil
+			}
+		}
+	}
+}
+
+type panicMember8 struct {
+	start chan struct{}
+}
+
+func (member *panicMember8) Go(current context.Context) error {
+	<-member.start
+	panic("member panic")
+}
+
+func Test_GroupFailFast(t *testing.T) {
+	sequenceChecker := newSequenceChecker()
+	rootContext := context.NewRootContext(newNode("root"))
+
+	group, err := context.NewGroup(rootContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failingMember := &groupMember8{
+		name:            "failing",
+		fail:            make(chan error),
+		sequenceChecker: sequenceChecker,
+	}
+
+	if _, err := group.NewContextFor(failingMember); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := group.NewContextFor(&groupMember8{
+			name:            fmt.Sprintf("member[%v]", i),
+			sequenceChecker: sequenceChecker,
+			sequenceStep:    3,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sequenceChecker.NotifyWithText(1, "failing one member\n")
+	expectedErr := errors.New("member error")
+	failingMember.fail <- expectedErr
+
+	<-group.Done()
+	sequenceChecker.NotifyWithText(4, "group done\n")
+
+	if group.Err() != expectedErr {
+		t.Fatalf("unexpected group error: %v", group.Err())
+	}
+
+	_, err = group.NewContextFor(&groupMember8{})
+	if err == nil {
+		t.Fatal("closed group accepted new member")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		rootContext.Close()
+	}()
+
+	rootContext.Wait()
+
+	fmt.Printf("test finished with correct sequence = %v\n", sequenceChecker.ToString())
+}
+
+func Test_GroupMemberPanic(t *testing.T) {
+	rootContext := context.NewRootContext(newNode("root"))
+
+	group, err := context.NewGroup(rootContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	member := &panicMember8{start: make(chan struct{})}
+	if _, err := group.NewContextFor(member); err != nil {
+		t.Fatal(err)
+	}
+	close(member.start)
+
+	<-group.Done()
+
+	panicErr, ok := group.Err().(*context.PanicError)
+	if !ok {
+		t.Fatalf("expected PanicError, got %v", group.Err())
+	}
+	fmt.Printf("successfully catched error: %v\n", panicErr)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
@@ -0,0 +1,169 @@
+package context_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	context "github.com/mcfly722/context"
+)
+
+type resource9 struct {
+	name            string
+	setupTime       time.Duration
+	isReady         bool
+	sequenceChecker sequenceChecker
+	ready           sync.Mutex
+}
+
+func (resource *resource9) getIsReady() bool {
+	resource.ready.Lock()
+	defer resource.ready.Unlock()
+	return resource.isReady
+}
+
+func (resource *resource9) Go(current context.Context) {
+	time.Sleep(resource.setupTime)
+
+	resource.ready.Lock()
+	resource.isReady = true
+	resource.ready.Unlock()
+
+	resource.sequenceChecker.NotifyWithText(1, "%v is ready\n", resource.name)
+	current.Ready()
+
+	<-current.Context()
+	resource.sequenceChecker.NotifyWithText(4, "%v finished\n", resource.name)
+}
+
+type user9 struct {
+	name            string
+	resource        *resource9
+	started         chan struct{}
+	sequenceChecker sequenceChecker
+}
+
+func (user *user9) Go(current context.Context) {
+	if !user.resource.getIsReady() {
+		panic(fmt.Sprintf("%v started before %v is ready", user.name, user.resource.name))
+	}
+	user.sequenceChecker.NotifyWithText(2, "%v started\n", user.name)
+	close(user.started)
+
+	<-current.Context()
+	user.sequenceChecker.NotifyWithText(3, "%v finished\n", user.name)
+}
+
+func Test_ReadinessAndDependencies(t *testing.T) {
+	sequenceChecker := newSequenceChecker()
+
+	rootContext := context.NewRootContext(newNode("root"))
+
+	database := &resource9{
+		name:            "database",
+		setupTime:       50 * time.Millisecond,
+		sequenceChecker: sequenceChecker,
+	}
+
+	databaseContext, err := rootContext.NewContextFor(database, context.WithReadiness())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	child := &user9{name: "child", resource: database, started: make(chan struct{}), sequenceChecker: sequenceChecker}
+	if _, err := databaseContext.NewContextFor(child); err != nil {
+		t.Fatal(err)
+	}
+
+	dependant := &user9{name: "dependant", resource: database, started: make(chan struct{}), sequenceChecker: sequenceChecker}
+	if _, err := rootContext.NewContextFor(dependant, context.DependsOn(databaseContext)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rootContext.WaitReady(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	<-child.started
+	<-dependant.started
+
+	rootContext.Close()
+	rootContext.Wait()
+
+	fmt.Printf("test finished with correct sequence = %v\n", sequenceChecker.ToString())
+}
+
+type neverReady9 struct{}
+
+func (node *neverReady9) Go(current context.Context) {
+	<-current.Context()
+}
+
+type mustNotStart9 struct{}
+
+func (node *mustNotStart9) Go(current context.Context) {
+	panic("node started before its parent is ready")
+}
+
+func Test_WaitReadyTimeout(t *testing.T) {
+	rootContext := context.NewRootContext(newNode("root"))
+
+	parentContext, err := rootContext.NewContextFor(&neverReady9{}, context.WithReadiness())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parentContext.NewContextFor(&mustNotStart9{}); err != nil {
+		t.Fatal(err)
+	}
+
+	err = rootContext.WaitReady(20 * time.Millisecond)
+	timeoutErr, ok := err.(*context.ReadyTimeoutError)
+	if !ok {
+		t.Fatalf("expected ReadyTimeoutError, got %v", err)
+	}
+	if timeoutErr.NotReady != 2 {
+		t.Fatalf("expected 2 not ready contexts, got %v", timeoutErr.NotReady)
+	}
+	fmt.Printf("successfully catched error: %v\n", err)
+
+	rootContext.Close()
+	rootContext.Wait()
+}
+
+func Test_DependencyCycle(t *testing.T) {
+	rootContext := context.NewRootContext(newNode13("root"))
+
+	a, b := newNode13("a"), newNode13("b")
+
+	aContext, err := rootContext.NewContextFor(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bContext, err := aContext.NewContextFor(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// existing node a could not depend on its own child or on itself
+	for _, dependency := range []context.ChildContext{bContext, aContext} {
+		if _, err := rootContext.NewContextFor(a, context.DependsOn(dependency)); err == nil {
+			t.Fatalf("cycle through %v is not rejected", dependency.Info().Name)
+		} else if _, ok := err.(*context.CycleError); !ok {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := bContext.NewContextFor(a); err == nil {
+		t.Fatal("node is added as a child of its own descendant")
+	}
+
+	if stats := rootContext.Stats(); stats.MaxDepth != 3 {
+		t.Fatalf("unexpected depth %v", stats.MaxDepth)
+	}
+
+	rootContext.Close()
+	rootContext.Wait()
+}
@@ -22,3 +22,11 @@ package context
 type ContextedInstance interface {
 	Go(current Context)
 }
+
+// Optional interface for your nodes. If node implements it, its name is used in reports, lookups and paths.
+// Otherwise, the name of the instance type is used.
+//
+// Name() is called only once, when context is created.
+type NamedInstance interface {
+	Name() string
+}
@@ -1,5 +1,9 @@
 package context
 
+import (
+	"fmt"
+)
+
 type ClosingIsInProcessForFreezeError struct{}
 
 func (err *ClosingIsInProcessForFreezeError) Error() string {
@@ -11,3 +15,89 @@ type ClosingIsInProcessForDisposingError struct{}
 func (err *ClosingIsInProcessForDisposingError) Error() string {
 	return "Closing is in process. Current context state=disposing. You cannot bind a new child to context during the closing parent context."
 }
+
+// ContextIsFinishedError returned when a context is already removed from the tree (its Go() method returned).
+type ContextIsFinishedError struct{}
+
+func (err *ContextIsFinishedError) Error() string {
+	return "Context is finished. You cannot bind a new child to context which Go() method already returned."
+}
+
+// ForeignContextError returned when a context from another tree (or not created by this module) is used.
+type ForeignContextError struct{}
+
+func (err *ForeignContextError) Error() string {
+	return "Context belongs to another context tree."
+}
+
+// NotLinkedError returned when a context is not a child of the specified parent.
+type NotLinkedError struct{}
+
+func (err *NotLinkedError) Error() string {
+	return "Context is not a child of specified parent."
+}
+
+// CycleError returned when a context is moved under itself or one of its descendants, or when an existing context gets itself or its descendant as a parent (or dependency).
+type CycleError struct{}
+
+func (err *CycleError) Error() string {
+	return "Context cannot be a child of itself or its own descendant."
+}
+
+// ReadyTimeoutError returned by WaitReady(...) when some contexts are still not ready.
+type ReadyTimeoutError struct {
+	NotReady int
+}
+
+func (err *ReadyTimeoutError) Error() string {
+	return fmt.Sprintf("Timeout while waiting for readiness. %v context(s) are still not ready.", err.NotReady)
+}
+
+// PoolSizeOutOfRangeError returned when pool is scaled out of its [PoolConfig] Min and Max limits.
+type PoolSizeOutOfRangeError struct {
+	Size int
+	Min  int
+	Max  int
+}
+
+func (err *PoolSizeOutOfRangeError) Error() string {
+	return fmt.Sprintf("Pool size %v is out of range [%v, %v].", err.Size, err.Min, err.Max)
+}
+
+// ExpvarIsAlreadyPublishedError returned by PublishExpvar(...) when the name is already used.
+type ExpvarIsAlreadyPublishedError struct {
+	Name string
+}
+
+func (err *ExpvarIsAlreadyPublishedError) Error() string {
+	return fmt.Sprintf("Expvar variable %v is already published.", err.Name)
+}
+
+// PanicError is returned by [Group.Err] when one of the group members panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("Group member panicked: %v", err.Value)
+}
+
+// JournalVersionError returned by ReadDump(...) when the dump is written by an unsupported version of the library.
+type JournalVersionError struct {
+	Version int
+}
+
+func (err *JournalVersionError) Error() string {
+	return fmt.Sprintf("Journal version %v is not supported, expected %v.", err.Version, JournalVersion)
+}
+
+// JournalFormatError returned by ReadDump(...) when the dump line could not be decoded.
+type JournalFormatError struct {
+	Line   int
+	Reason string
+}
+
+func (err *JournalFormatError) Error() string {
+	return fmt.Sprintf("Journal line %v is invalid: %v.", err.Line, err.Reason)
+}
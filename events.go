@@ -0,0 +1,73 @@
+package context
+
+import (
+	"time"
+)
+
+type eventKind int
+
+const (
+	eventCreated eventKind = iota
+	eventLinked
+	eventStateChanged
+	eventExitedEarly
+	eventPanicked
+	eventRejected
+	eventAdopted
+	eventUnlinked
+	eventFailed
+)
+
+// lifecycle event of one node, it is reported to observers while the tree is locked
+type event struct {
+	kind eventKind
+	node *context
+	// creating parent for eventCreated, new parent for eventLinked, adopter for eventAdopted and former parent for eventUnlinked
+	parent *context
+	// previous state for eventStateChanged
+	from State
+	// error for eventRejected and eventFailed, recovered PanicError for eventPanicked (nil if the panic crashes the process)
+	err error
+	at  time.Time
+}
+
+// observers are called while the tree is locked, so they must be fast and must not call any context methods
+type observer interface {
+	observe(event *event)
+}
+
+func (event *event) String() string {
+	switch event.kind {
+	case eventCreated:
+		return "created"
+	case eventLinked:
+		return "linked"
+	case eventStateChanged:
+		return event.node.state.String()
+	case eventExitedEarly:
+		return "exited early"
+	case eventPanicked:
+		return "panicked"
+	case eventRejected:
+		return "rejected: " + event.err.Error()
+	case eventAdopted:
+		return "adopted by " + event.parent.path
+	case eventUnlinked:
+		return "unlinked from " + event.parent.path
+	case eventFailed:
+		return "failed: " + event.err.Error()
+	}
+	return "unknown"
+}
+
+func (root *root) emit(event *event) {
+	if len(root.observers) == 0 {
+		return
+	}
+
+	event.at = time.Now()
+
+	for _, observer := range root.observers {
+		observer.observe(event)
+	}
+}
@@ -0,0 +1,68 @@
+package context
+
+import (
+	"path"
+	"sort"
+)
+
+// Selector chooses contexts for RootContext Find(...) and FindAll(...) methods.
+// You could use [ByID], [ByName], [ByPath], [ByType] or write your own one.
+// Selector is called while the tree is locked, so it must not call any context methods.
+type Selector func(info NodeInfo) bool
+
+// ByID selects the context with specified ID.
+func ByID(id uint64) Selector {
+	return func(info NodeInfo) bool {
+		return info.ID == id
+	}
+}
+
+// ByName selects contexts with specified name (see [NamedInstance]).
+func ByName(name string) Selector {
+	return func(info NodeInfo) bool {
+		return info.Name == name
+	}
+}
+
+// ByPath selects contexts which path matches the pattern. Pattern syntax is the same as for [path.Match], e.g. "root/workers/*".
+// Brackets are pattern symbols there, so name "worker[1]" should be escaped as "worker\[1\]".
+func ByPath(pattern string) Selector {
+	return func(info NodeInfo) bool {
+		matched, err := path.Match(pattern, info.Path)
+		return err == nil && matched
+	}
+}
+
+// ByType selects contexts which instance has the same type as sample, e.g. ByType(&worker{}).
+func ByType(sample interface{}) Selector {
+	sampleType := typeOf(sample)
+	return func(info NodeInfo) bool {
+		return info.Type == sampleType
+	}
+}
+
+// returns contexts matched selector ordered by ID, limit=0 means no limit
+func (root *root) find(selector Selector, limit int) []ChildContext {
+	root.ready.Lock()
+	defer root.ready.Unlock()
+
+	nodes := []*context{}
+	for _, node := range root.contexts {
+		if selector(node.info()) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+
+	if limit > 0 && len(nodes) > limit {
+		nodes = nodes[:limit]
+	}
+
+	found := make([]ChildContext, len(nodes))
+	for i, node := range nodes {
+		found[i] = node
+	}
+
+	return found
+}
@@ -0,0 +1,141 @@
+package context
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// This interface should be implemented by the members of a [Group].
+//
+// It is the same as [ContextedInstance], but Go(...) could return an error. The first member that returns an error (or panics) closes the whole group.
+type FallibleInstance interface {
+	Go(current Context) error
+}
+
+// Group obtained from the [NewGroup] function.
+//
+// All group members are children of one group node. The first member that fails closes the group node, so all members close in reverse order as any other subtree.
+// The group node itself does not exit until it is closed (by the first failure, by its parent or explicitly), members that return nil just leave the group.
+type Group interface {
+
+	// creates a new group member context
+	NewContextFor(instance FallibleInstance, options ...Option) (ChildContext, error)
+
+	// This channel closes when the group and all its members are closed. After that [Group.Err] returns the final result.
+	Done() chan struct{}
+
+	// Returns the first error returned by one of the members (or [PanicError] if member panicked), nil if there was no error.
+	Err() error
+
+	// Close the group and all its members in reverse order.
+	Close()
+}
+
+type group struct {
+	context ChildContext
+	done    chan struct{}
+	err     error
+	ready   sync.Mutex
+}
+
+type groupMember struct {
+	group    *group
+	instance FallibleInstance
+}
+
+// NewGroup creates a new group node as a child of the parent context.
+//
+// The parent should select on [Group.Done] in its Go loop to obtain the group result with [Group.Err].
+func NewGroup(parent ChildContext) (Group, error) {
+	group := &group{
+		done: make(chan struct{}),
+	}
+
+	groupContext, err := parent.NewContextFor(group)
+	if err != nil {
+		return nil, err
+	}
+
+	group.context = groupContext
+
+	return group, nil
+}
+
+// NewContextFor ...
+func (group *group) NewContextFor(instance FallibleInstance, options ...Option) (ChildContext, error) {
+	return group.context.NewContextFor(&groupMember{
+		group:    group,
+		instance: instance,
+	}, options...)
+}
+
+// Done ...
+func (group *group) Done() chan struct{} {
+	return group.done
+}
+
+// Err ...
+func (group *group) Err() error {
+	group.ready.Lock()
+	defer group.ready.Unlock()
+	return group.err
+}
+
+// Close ...
+func (group *group) Close() {
+	group.context.Close()
+}
+
+func (group *group) fail(err error) {
+	group.ready.Lock()
+	first := group.err == nil
+	if first {
+		group.err = err
+	}
+	group.ready.Unlock()
+
+	if groupContext := contextOf(group.context); first && groupContext != nil {
+		groupContext.failed(err)
+	}
+
+	group.context.Close()
+}
+
+func (group *group) Go(current Context) {
+loop:
+	for {
+		select {
+		case _, isOpened := <-current.Context():
+			if !isOpened {
+				break loop
+			}
+		}
+	}
+	close(group.done)
+}
+
+func (member *groupMember) unwrap() interface{} {
+	return member.instance
+}
+
+func (member *groupMember) Go(current Context) {
+	if err := member.run(current); err != nil {
+		member.group.fail(err)
+	}
+}
+
+func (member *groupMember) run(current Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{
+				Value: r,
+				Stack: debug.Stack(),
+			}
+			if panicked, ok := current.(*context); ok {
+				panicked.recovered(err)
+			}
+		}
+	}()
+
+	return member.instance.Go(current)
+}
@@ -0,0 +1,148 @@
+package context
+
+import (
+	"sort"
+)
+
+// Optional interface for your nodes. If node implements it, its result is included into [RootContext] Health() report.
+//
+// CheckHealth() is called from the Health() caller goroutine, so it should be safe for concurrent use with the node Go() method.
+type HealthChecker interface {
+	CheckHealth() error
+}
+
+// HealthStatus of the node or of the whole tree. Statuses are ordered from the best to the worst one.
+type HealthStatus int
+
+const (
+	// node works and its health check (if any) succeeded
+	Healthy HealthStatus = 0
+	// node is not started or is not ready yet (see [WithReadiness])
+	Starting HealthStatus = 1
+	// node is in freezed or disposing state
+	ShuttingDown HealthStatus = 2
+	// node health check returned an error
+	Unhealthy HealthStatus = 3
+)
+
+func (status HealthStatus) String() string {
+	switch status {
+	case Healthy:
+		return "healthy"
+	case Starting:
+		return "starting"
+	case ShuttingDown:
+		return "shutting down"
+	case Unhealthy:
+		return "unhealthy"
+	}
+	return "unknown"
+}
+
+// NodeHealth is the health of one node.
+type NodeHealth struct {
+	NodeInfo
+	Status HealthStatus
+	// error returned by the node health check
+	Err error
+}
+
+// HealthReport returned by RootContext Health() method.
+type HealthReport struct {
+	// the worst status of all nodes
+	Status HealthStatus
+	// all nodes of the tree ordered by ID
+	Nodes []NodeHealth
+}
+
+// Live returns false only if some node health check failed. Use it for liveness probes.
+func (report HealthReport) Live() bool {
+	return report.Status != Unhealthy
+}
+
+// Ready returns true only if all nodes are started, ready and healthy. Use it for readiness probes.
+func (report HealthReport) Ready() bool {
+	return report.Status == Healthy
+}
+
+type healthCandidate struct {
+	health   NodeHealth
+	instance interface{}
+}
+
+func (current *context) health() NodeHealth {
+	status := Healthy
+
+	switch {
+	case current.state == Freezed || current.state == Disposing:
+		status = ShuttingDown
+	case current.state == NotStarted || !current.isReady:
+		status = Starting
+	}
+
+	return NodeHealth{
+		NodeInfo: current.info(),
+		Status:   status,
+	}
+}
+
+// walks the tree from current node and returns all nodes ordered by ID, every node only once
+func (current *context) subtree() []*context {
+	visited := map[*context]bool{}
+	nodes := []*context{}
+
+	var walk func(node *context)
+	walk = func(node *context) {
+		if visited[node] {
+			return
+		}
+		visited[node] = true
+		nodes = append(nodes, node)
+		for child := range node.childs {
+			walk(child)
+		}
+	}
+	walk(current)
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+
+	return nodes
+}
+
+func (current *context) healthReport() HealthReport {
+	candidates := []healthCandidate{}
+
+	{
+		current.root.ready.Lock()
+		for _, node := range current.subtree() {
+			candidates = append(candidates, healthCandidate{
+				health:   node.health(),
+				instance: unwrap(node.instance),
+			})
+		}
+		current.root.ready.Unlock()
+	}
+
+	// health checks are user code, so they are called without tree lock
+	report := HealthReport{
+		Status: Healthy,
+		Nodes:  make([]NodeHealth, 0, len(candidates)),
+	}
+
+	for _, candidate := range candidates {
+		if checker, ok := candidate.instance.(HealthChecker); ok && candidate.health.Status == Healthy {
+			if err := checker.CheckHealth(); err != nil {
+				candidate.health.Status = Unhealthy
+				candidate.health.Err = err
+			}
+		}
+
+		if candidate.health.Status > report.Status {
+			report.Status = candidate.health.Status
+		}
+
+		report.Nodes = append(report.Nodes, candidate.health)
+	}
+
+	return report
+}
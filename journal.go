@@ -0,0 +1,472 @@
+package context
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// JournalVersion is the version of the journal dump format written by [Journal].
+const JournalVersion = 1
+
+// reasons of journal dumps
+const (
+	DumpOnDemand = "demand"
+	DumpOnStall  = "stall"
+	DumpOnCrash  = "crash"
+	DumpOnSignal = "signal"
+)
+
+// JournalConfig configures [Journal].
+type JournalConfig struct {
+	// number of last events kept in memory, 1024 by default
+	Size int
+	// directory for automatic dumps, without it dumps are written only by WriteTo and WriteFile calls
+	Dir string
+	// dump is written if the tree is not finished in StallTimeout after the root context is freezed, zero disables it
+	StallTimeout time.Duration
+	// dump is written on SIGQUIT, then the signal is raised again, so Go prints goroutines and exits as usual (not supported on windows)
+	DumpOnSIGQUIT bool
+}
+
+// Journal is a flight recorder: it keeps last lifecycle events of the tree in a ring buffer and dumps them with the snapshot of the tree.
+//
+// Dump is a versioned JSON-lines file: header line, then a line for every live context, then a line for every event (see [ReadDump]).
+// Besides WriteTo and WriteFile calls, dumps are written to JournalConfig.Dir when the shutdown stalls, when Go method of any context panics, and on SIGQUIT.
+//
+// Pass it to [NewRootContext] with [WithJournal] option. One journal records only one tree.
+type Journal struct {
+	config  JournalConfig
+	events  []JournalEvent
+	next    int
+	dropped uint64
+	tree    *root
+	stall   *time.Timer
+	signals chan os.Signal
+	ready   sync.Mutex
+}
+
+// JournalHeader is the first line of the dump.
+type JournalHeader struct {
+	Version   int       `json:"version"`
+	Reason    string    `json:"reason"`
+	WrittenAt time.Time `json:"written_at"`
+	// number of events dropped from the ring buffer
+	Dropped uint64 `json:"dropped"`
+	// false if the tree was locked too long on SIGQUIT, so only events were written
+	Snapshot bool `json:"snapshot"`
+}
+
+// JournalNode is the live context at the moment of the dump.
+type JournalNode struct {
+	NodeInfo
+	State string `json:"state"`
+	// time of the last state change
+	Since   time.Time `json:"since"`
+	Parents []uint64  `json:"parents"`
+}
+
+// JournalEvent is one lifecycle event of the context.
+type JournalEvent struct {
+	At time.Time `json:"at"`
+	// created, linked, unlinked, working, freezed, disposing, finished, exited early, panicked, error (spawned task or group failure), rejected or adopted
+	Kind string   `json:"kind"`
+	Node NodeInfo `json:"node"`
+	// creating parent, linked or unlinked parent, or adopter
+	Parent *NodeInfo `json:"parent,omitempty"`
+	// previous state for state changes
+	From  string `json:"from,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// JournalDump is the decoded dump (see [ReadDump]).
+type JournalDump struct {
+	Header JournalHeader
+	Nodes  []JournalNode
+	Events []JournalEvent
+}
+
+// every line of the dump has only one of the fields
+type journalLine struct {
+	Header *JournalHeader `json:"header,omitempty"`
+	Node   *JournalNode   `json:"node,omitempty"`
+	Event  *JournalEvent  `json:"event,omitempty"`
+}
+
+// NewJournal creates empty journal.
+func NewJournal(config JournalConfig) *Journal {
+	if config.Size <= 0 {
+		config.Size = 1024
+	}
+
+	journal := &Journal{
+		config: config,
+		events: make([]JournalEvent, 0, config.Size),
+	}
+
+	if config.DumpOnSIGQUIT && config.Dir != "" {
+		journal.watchSignals()
+	}
+
+	return journal
+}
+
+// WithJournal records lifecycle events of all tree contexts to journal (see [Journal]).
+//
+// This option is used only by [NewRootContext].
+func WithJournal(journal *Journal) Option {
+	return func(options *options) {
+		options.observers = append(options.observers, journal)
+	}
+}
+
+// Close stops automatic dumps on stall and on SIGQUIT.
+func (journal *Journal) Close() {
+	journal.ready.Lock()
+	defer journal.ready.Unlock()
+
+	if journal.stall != nil {
+		journal.stall.Stop()
+	}
+	journal.stopSignals()
+}
+
+func (journal *Journal) observe(event *event) {
+	journal.ready.Lock()
+	defer journal.ready.Unlock()
+
+	if journal.tree == nil {
+		journal.tree = event.node.root
+	}
+
+	journal.add(journalEventOf(event))
+
+	switch {
+	case event.kind == eventStateChanged && event.node == journal.tree.node:
+		switch event.node.state {
+		case Freezed:
+			if journal.config.StallTimeout > 0 && journal.config.Dir != "" {
+				journal.stall = time.AfterFunc(journal.config.StallTimeout, func() {
+					journal.dump(DumpOnStall)
+				})
+			}
+		case Finished:
+			if journal.stall != nil {
+				journal.stall.Stop()
+			}
+		}
+	case event.kind == eventPanicked && event.err == nil && journal.config.Dir != "":
+		// the panic continues after this event and crashes the process, so the dump is written right now
+		journal.writeDir(DumpOnCrash, true)
+	}
+}
+
+func (journal *Journal) add(event JournalEvent) {
+	if len(journal.events) < cap(journal.events) {
+		journal.events = append(journal.events, event)
+		return
+	}
+
+	journal.events[journal.next] = event
+	journal.next = (journal.next + 1) % len(journal.events)
+	journal.dropped++
+}
+
+func journalEventOf(event *event) JournalEvent {
+	journalEvent := JournalEvent{
+		At:   event.at,
+		Node: event.node.info(),
+	}
+
+	if event.parent != nil && event.parent.instance != nil {
+		parent := event.parent.info()
+		journalEvent.Parent = &parent
+	}
+
+	switch event.kind {
+	case eventCreated:
+		journalEvent.Kind = "created"
+	case eventLinked:
+		journalEvent.Kind = "linked"
+	case eventStateChanged:
+		journalEvent.Kind = event.node.state.String()
+		journalEvent.From = event.from.String()
+	case eventExitedEarly:
+		journalEvent.Kind = "exited early"
+	case eventPanicked:
+		journalEvent.Kind = "panicked"
+		if event.err != nil {
+			journalEvent.Error = event.err.Error()
+		}
+	case eventRejected:
+		journalEvent.Kind = "rejected"
+		journalEvent.Error = event.err.Error()
+	case eventAdopted:
+		journalEvent.Kind = "adopted"
+	case eventUnlinked:
+		journalEvent.Kind = "unlinked"
+	case eventFailed:
+		journalEvent.Kind = "error"
+		journalEvent.Error = event.err.Error()
+	}
+
+	return journalEvent
+}
+
+// writes dump to the config directory if the reason is still actual, takes locks itself
+func (journal *Journal) dump(reason string) {
+	journal.ready.Lock()
+	tree := journal.tree
+	journal.ready.Unlock()
+
+	if tree == nil {
+		return
+	}
+
+	tree.ready.Lock()
+	defer tree.ready.Unlock()
+
+	journal.ready.Lock()
+	defer journal.ready.Unlock()
+
+	if reason == DumpOnStall && tree.node.state == Finished {
+		return
+	}
+
+	journal.writeDir(reason, true)
+}
+
+// on SIGQUIT the tree lock could be stuck (e.g. in user code called under it), so the dump waits for it only a little and writes events without the snapshot
+func (journal *Journal) dumpOnSignal() {
+	journal.ready.Lock()
+	tree := journal.tree
+	journal.ready.Unlock()
+
+	locked := tree != nil && lockWithTimeout(&tree.ready, signalLockTimeout)
+	if locked {
+		defer tree.ready.Unlock()
+	}
+
+	journal.ready.Lock()
+	defer journal.ready.Unlock()
+
+	journal.writeDir(DumpOnSignal, locked)
+}
+
+const signalLockTimeout = 500 * time.Millisecond
+
+// sync.Mutex has no TryLock in go 1.17, so the lock is taken by a helper goroutine which releases it if nobody waits anymore
+func lockWithTimeout(mutex *sync.Mutex, timeout time.Duration) bool {
+	acquired := make(chan struct{})
+	abandoned := make(chan struct{})
+
+	go func() {
+		mutex.Lock()
+		select {
+		case acquired <- struct{}{}:
+		case <-abandoned:
+			mutex.Unlock()
+		}
+	}()
+
+	select {
+	case <-acquired:
+		return true
+	case <-time.After(timeout):
+		close(abandoned)
+		return false
+	}
+}
+
+// must be called under journal lock, and under tree lock if the snapshot is written
+func (journal *Journal) writeDir(reason string, snapshot bool) {
+	name := fmt.Sprintf("journal-%v-%v.jsonl", time.Now().Format("20060102T150405.000000000"), reason)
+	path := filepath.Join(journal.config.Dir, name)
+
+	if err := journal.writeFile(path, reason, snapshot); err != nil && journal.tree != nil {
+		journal.tree.logger.Error("journal dump failed", "path", path, "error", err)
+	}
+}
+
+func (journal *Journal) writeFile(path string, reason string, snapshot bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err = journal.write(file, reason, snapshot); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// WriteTo writes dump of the journal and the tree snapshot.
+func (journal *Journal) WriteTo(writer io.Writer) (int64, error) {
+	journal.ready.Lock()
+	tree := journal.tree
+	journal.ready.Unlock()
+
+	if tree != nil {
+		tree.ready.Lock()
+		defer tree.ready.Unlock()
+	}
+
+	journal.ready.Lock()
+	defer journal.ready.Unlock()
+
+	return journal.write(writer, DumpOnDemand, true)
+}
+
+// WriteFile writes dump of the journal and the tree snapshot to the file.
+func (journal *Journal) WriteFile(path string) error {
+	journal.ready.Lock()
+	tree := journal.tree
+	journal.ready.Unlock()
+
+	if tree != nil {
+		tree.ready.Lock()
+		defer tree.ready.Unlock()
+	}
+
+	journal.ready.Lock()
+	defer journal.ready.Unlock()
+
+	return journal.writeFile(path, DumpOnDemand, true)
+}
+
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (writer *countingWriter) Write(data []byte) (int, error) {
+	n, err := writer.writer.Write(data)
+	writer.count += int64(n)
+	return n, err
+}
+
+// must be called under journal lock, and under tree lock if the snapshot is written
+func (journal *Journal) write(writer io.Writer, reason string, snapshot bool) (int64, error) {
+	counter := &countingWriter{writer: writer}
+	buffered := bufio.NewWriter(counter)
+	encoder := json.NewEncoder(buffered)
+
+	header := &JournalHeader{
+		Version:   JournalVersion,
+		Reason:    reason,
+		WrittenAt: time.Now(),
+		Dropped:   journal.dropped,
+		Snapshot:  snapshot,
+	}
+
+	if err := encoder.Encode(&journalLine{Header: header}); err != nil {
+		return counter.count, err
+	}
+
+	if snapshot {
+		for _, node := range journal.snapshot() {
+			if err := encoder.Encode(&journalLine{Node: node}); err != nil {
+				return counter.count, err
+			}
+		}
+	}
+
+	for i := range journal.events {
+		event := journal.events[(journal.next+i)%len(journal.events)]
+		if err := encoder.Encode(&journalLine{Event: &event}); err != nil {
+			return counter.count, err
+		}
+	}
+
+	err := buffered.Flush()
+
+	return counter.count, err
+}
+
+func (journal *Journal) snapshot() []*JournalNode {
+	nodes := []*JournalNode{}
+	if journal.tree == nil {
+		return nodes
+	}
+
+	contexts := []*context{}
+	for _, current := range journal.tree.contexts {
+		contexts = append(contexts, current)
+	}
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].id < contexts[j].id })
+
+	for _, current := range contexts {
+		node := &JournalNode{
+			NodeInfo: current.info(),
+			State:    current.state.String(),
+			Since:    current.at[current.state],
+			Parents:  []uint64{},
+		}
+
+		for _, parent := range sortedByID(current.parents) {
+			if parent.instance != nil {
+				node.Parents = append(node.Parents, parent.id)
+			}
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+// ReadDump decodes the dump written by [Journal].
+func ReadDump(reader io.Reader) (*JournalDump, error) {
+	dump := &JournalDump{
+		Nodes:  []JournalNode{},
+		Events: []JournalEvent{},
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	number := 0
+	for scanner.Scan() {
+		number++
+
+		line := journalLine{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, &JournalFormatError{Line: number, Reason: err.Error()}
+		}
+
+		switch {
+		case number == 1 && line.Header == nil:
+			return nil, &JournalFormatError{Line: number, Reason: "header expected"}
+		case line.Header != nil:
+			if number != 1 {
+				return nil, &JournalFormatError{Line: number, Reason: "unexpected header"}
+			}
+			if line.Header.Version != JournalVersion {
+				return nil, &JournalVersionError{Version: line.Header.Version}
+			}
+			dump.Header = *line.Header
+		case line.Node != nil:
+			dump.Nodes = append(dump.Nodes, *line.Node)
+		case line.Event != nil:
+			dump.Events = append(dump.Events, *line.Event)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if number == 0 {
+		return nil, &JournalFormatError{Line: 1, Reason: "header expected"}
+	}
+
+	return dump, nil
+}
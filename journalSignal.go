@@ -0,0 +1,36 @@
+//go:build !windows
+
+package context
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func (journal *Journal) watchSignals() {
+	journal.signals = make(chan os.Signal, 1)
+	signal.Notify(journal.signals, syscall.SIGQUIT)
+
+	go func(signals chan os.Signal) {
+		if _, isOpened := <-signals; !isOpened {
+			return
+		}
+
+		// restore default behaviour even if the dump fails, Go prints all goroutines and exits
+		defer func() {
+			signal.Reset(syscall.SIGQUIT)
+			syscall.Kill(os.Getpid(), syscall.SIGQUIT)
+		}()
+
+		journal.dumpOnSignal()
+	}(journal.signals)
+}
+
+func (journal *Journal) stopSignals() {
+	if journal.signals != nil {
+		signal.Stop(journal.signals)
+		close(journal.signals)
+		journal.signals = nil
+	}
+}
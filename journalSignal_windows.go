@@ -0,0 +1,6 @@
+package context
+
+// there is no SIGQUIT on windows
+func (journal *Journal) watchSignals() {}
+
+func (journal *Journal) stopSignals() {}
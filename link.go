@@ -0,0 +1,144 @@
+package context
+
+// OrphanPolicy defines what happens with a child context when it loses its last parent.
+type OrphanPolicy int
+
+const (
+	// orphan is closed with all its children
+	FreezeOrphan OrphanPolicy = 0
+	// orphan becomes a child of the root context and keeps working
+	AdoptOrphanByRoot OrphanPolicy = 1
+	// orphan becomes a child of the reaper context (see [WithOrphanReaper]) and keeps working
+	AdoptOrphanByReaper OrphanPolicy = 2
+)
+
+// Move re-parents a live child context from one parent to another without restarting it.
+//
+// The target parent must not be in closing state, and it must not be the child itself or one of its descendants.
+// Other parents of the child (see multi-parent contexts) are not changed.
+func Move(child ChildContext, fromParent ChildContext, toParent ChildContext) error {
+	current, from, to := contextOf(child), contextOf(fromParent), contextOf(toParent)
+	if current == nil || from == nil || to == nil || current.root != from.root || current.root != to.root {
+		return &ForeignContextError{}
+	}
+
+	current.root.ready.Lock()
+	defer current.root.ready.Unlock()
+
+	if current.state == Finished {
+		return &ContextIsFinishedError{}
+	}
+
+	if _, linked := current.parents[from]; !linked {
+		return &NotLinkedError{}
+	}
+
+	if err := to.checkIsNotClosing(); err != nil {
+		return err
+	}
+
+	if current.isAncestorOf(to) {
+		return &CycleError{}
+	}
+
+	current.unlink(from)
+	current.link(to)
+
+	// not started child could be started by new parent
+	current.tryStart()
+
+	return nil
+}
+
+// Unlink removes a single edge between parent and child without closing the child.
+//
+// If child has no other parents, it becomes an orphan and is handled according to policy. Root (or reaper) context could not adopt orphans when it is closing, in this case orphan is closed.
+func Unlink(parent ChildContext, child ChildContext, policy OrphanPolicy) error {
+	current, from := contextOf(child), contextOf(parent)
+	if current == nil || from == nil || current.root != from.root {
+		return &ForeignContextError{}
+	}
+
+	current.root.ready.Lock()
+	defer current.root.unlock()
+
+	if current.state == Finished {
+		return &ContextIsFinishedError{}
+	}
+
+	if _, linked := current.parents[from]; !linked {
+		return &NotLinkedError{}
+	}
+
+	current.unlink(from)
+
+	if len(current.parents) == 0 {
+		current.handleOrphan(policy)
+	} else {
+		// not started child could wait only for this parent
+		current.tryStart()
+	}
+
+	return nil
+}
+
+// called for a child which lost its last parent
+func (current *context) handleOrphan(policy OrphanPolicy) {
+	adopter := (*context)(nil)
+
+	switch policy {
+	case AdoptOrphanByRoot:
+		adopter = current.root.node
+	case AdoptOrphanByReaper:
+		adopter = current.root.reaper
+		// reaper is gone, the root is the last hope
+		if !adopter.canAdopt() {
+			adopter = current.root.node
+		}
+	}
+
+	if !adopter.canAdopt() || current.isAncestorOf(adopter) {
+		current.debug("orphan freezed", "reason", "no adopter")
+		current.freezeAllChildsAndSubchilds()
+		return
+	}
+
+	current.link(adopter)
+	current.root.emit(&event{kind: eventAdopted, node: current, parent: adopter})
+	current.tryStart()
+
+	if onAdopted := current.root.onAdopted; onAdopted != nil {
+		current.root.callbacks = append(current.root.callbacks, func() {
+			onAdopted(current, adopter)
+		})
+	}
+}
+
+// adopter must be alive: not closing and its Go method not returned (it could be the exiting node itself)
+func (current *context) canAdopt() bool {
+	return current != nil && !current.isReturned && current.state != Finished && current.checkIsNotClosing() == nil
+}
+
+func (current *context) isAncestorOf(node *context) bool {
+	visited := map[*context]bool{}
+
+	var walk func(ancestor *context) bool
+	walk = func(ancestor *context) bool {
+		if ancestor == node {
+			return true
+		}
+		if visited[ancestor] {
+			return false
+		}
+		visited[ancestor] = true
+
+		for child := range ancestor.childs {
+			if walk(child) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return walk(current)
+}
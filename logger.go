@@ -0,0 +1,70 @@
+package context
+
+// Logger is a structured logger, its methods have the same shape as [log/slog.Logger] ones, so *slog.Logger could be passed as is.
+// Args are alternating keys and values.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// WithLogger sets logger of the tree. By default nothing is logged.
+//
+// Library logs at debug level early exits of Go methods, freezes of orphans and NewContextFor calls rejected because of closing.
+// Logger is called under the tree lock, so it must not call any tree methods. Use [Context.Logger] in your instances.
+//
+// This option is used only by [NewRootContext].
+func WithLogger(logger Logger) Option {
+	return func(options *options) {
+		options.logger = logger
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+type contextLogger struct {
+	context *context
+}
+
+// Logger returns tree logger which adds context name, path and current state to every record.
+func (current *context) Logger() Logger {
+	return &contextLogger{context: current}
+}
+
+func (logger *contextLogger) with(args []interface{}) []interface{} {
+	logger.context.root.ready.Lock()
+	defer logger.context.root.ready.Unlock()
+
+	return append(logger.context.logAttributes(), args...)
+}
+
+func (logger *contextLogger) Debug(msg string, args ...interface{}) {
+	logger.context.root.logger.Debug(msg, logger.with(args)...)
+}
+
+func (logger *contextLogger) Info(msg string, args ...interface{}) {
+	logger.context.root.logger.Info(msg, logger.with(args)...)
+}
+
+func (logger *contextLogger) Warn(msg string, args ...interface{}) {
+	logger.context.root.logger.Warn(msg, logger.with(args)...)
+}
+
+func (logger *contextLogger) Error(msg string, args ...interface{}) {
+	logger.context.root.logger.Error(msg, logger.with(args)...)
+}
+
+func (current *context) logAttributes() []interface{} {
+	return []interface{}{"context", current.name, "path", current.path, "state", current.state.String()}
+}
+
+// logs library lifecycle records, must be called under the tree lock
+func (current *context) debug(msg string, args ...interface{}) {
+	current.root.logger.Debug(msg, append(current.logAttributes(), args...)...)
+}
@@ -0,0 +1,243 @@
+package context
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metrics collects lifecycle metrics of contexts labelled by context name and instance type.
+// Pass it to [NewRootContext] with [WithMetrics] option, one Metrics could be shared by several roots.
+//
+// Metrics implements [http.Handler] and serves them in OpenMetrics text format:
+//
+//	http.Handle("/metrics", metrics)
+type Metrics struct {
+	counters map[string]map[metricLabels]uint64
+	states   map[metricLabels]map[State]int
+	lifetime map[metricLabels]*histogram
+	drain    map[metricLabels]*histogram
+	ready    sync.Mutex
+}
+
+type metricLabels struct {
+	name         string
+	instanceType string
+}
+
+type histogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// upper bounds of histogram buckets in seconds
+var histogramBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+var metricCounters = []struct {
+	name string
+	help string
+}{
+	{name: "context_created", help: "Contexts created."},
+	{name: "context_exited_early", help: "Contexts which Go() method returned without closing."},
+	{name: "context_frozen", help: "Contexts moved to freezed state."},
+	{name: "context_disposed", help: "Contexts moved to disposing state."},
+	{name: "context_panicked", help: "Contexts which Go() method panicked and the panic was recovered (group members)."},
+}
+
+// NewMetrics creates empty metrics.
+func NewMetrics() *Metrics {
+	metrics := &Metrics{
+		counters: map[string]map[metricLabels]uint64{},
+		states:   map[metricLabels]map[State]int{},
+		lifetime: map[metricLabels]*histogram{},
+		drain:    map[metricLabels]*histogram{},
+	}
+
+	for _, counter := range metricCounters {
+		metrics.counters[counter.name] = map[metricLabels]uint64{}
+	}
+
+	return metrics
+}
+
+// WithMetrics reports lifecycle of all tree contexts to metrics (see [Metrics]).
+//
+// This option is used only by [NewRootContext].
+func WithMetrics(metrics *Metrics) Option {
+	return func(options *options) {
+		options.observers = append(options.observers, metrics)
+	}
+}
+
+func (metrics *Metrics) observe(event *event) {
+	metrics.ready.Lock()
+	defer metrics.ready.Unlock()
+
+	labels := metricLabels{
+		name:         event.node.name,
+		instanceType: typeOf(unwrap(event.node.instance)),
+	}
+
+	switch event.kind {
+	case eventCreated:
+		metrics.counters["context_created"][labels]++
+		metrics.changeState(labels, event.node.state, 1)
+	case eventExitedEarly:
+		metrics.counters["context_exited_early"][labels]++
+	case eventPanicked:
+		metrics.counters["context_panicked"][labels]++
+	case eventStateChanged:
+		metrics.changeState(labels, event.from, -1)
+
+		switch event.node.state {
+		case Freezed:
+			metrics.counters["context_frozen"][labels]++
+		case Disposing:
+			metrics.counters["context_disposed"][labels]++
+		case Finished:
+			at := event.node.at
+			if !at[Working].IsZero() {
+				metrics.histogram(metrics.lifetime, labels).observe(at[Finished].Sub(at[Working]).Seconds())
+			}
+			if !at[Freezed].IsZero() {
+				metrics.histogram(metrics.drain, labels).observe(at[Finished].Sub(at[Freezed]).Seconds())
+			}
+			return
+		}
+
+		metrics.changeState(labels, event.node.state, 1)
+	}
+}
+
+// finished contexts are not counted, so series with zero contexts are removed
+func (metrics *Metrics) changeState(labels metricLabels, state State, delta int) {
+	states := metrics.states[labels]
+	if states == nil {
+		states = map[State]int{}
+		metrics.states[labels] = states
+	}
+
+	states[state] += delta
+
+	if states[state] == 0 {
+		delete(states, state)
+	}
+	if len(states) == 0 {
+		delete(metrics.states, labels)
+	}
+}
+
+func (metrics *Metrics) histogram(histograms map[metricLabels]*histogram, labels metricLabels) *histogram {
+	if histograms[labels] == nil {
+		histograms[labels] = &histogram{
+			buckets: make([]uint64, len(histogramBuckets)),
+		}
+	}
+	return histograms[labels]
+}
+
+func (histogram *histogram) observe(value float64) {
+	for i, bound := range histogramBuckets {
+		if value <= bound {
+			histogram.buckets[i]++
+		}
+	}
+	histogram.count++
+	histogram.sum += value
+}
+
+// ServeHTTP ...
+func (metrics *Metrics) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	response.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	metrics.WriteTo(response)
+}
+
+// WriteTo writes metrics in OpenMetrics text format.
+func (metrics *Metrics) WriteTo(writer io.Writer) (int64, error) {
+	buffer := &bytes.Buffer{}
+
+	metrics.ready.Lock()
+
+	for _, counter := range metricCounters {
+		fmt.Fprintf(buffer, "# TYPE %v counter\n# HELP %v %v\n", counter.name, counter.name, counter.help)
+		values := metrics.counters[counter.name]
+		for _, labels := range sortedLabels(values) {
+			fmt.Fprintf(buffer, "%v_total{%v} %v\n", counter.name, labels.String(), values[labels])
+		}
+	}
+
+	buffer.WriteString("# TYPE context_state gauge\n# HELP context_state Contexts in every state.\n")
+	for _, labels := range sortedLabels(metrics.states) {
+		states := metrics.states[labels]
+		for state := NotStarted; state < Finished; state++ {
+			if count, found := states[state]; found {
+				fmt.Fprintf(buffer, "context_state{%v,state=\"%v\"} %v\n", labels.String(), state.String(), count)
+			}
+		}
+	}
+
+	writeHistograms(buffer, "context_lifetime_seconds", "Time from context start till its Go() method returned.", metrics.lifetime)
+	writeHistograms(buffer, "context_drain_seconds", "Time from context freeze till its Go() method returned.", metrics.drain)
+
+	metrics.ready.Unlock()
+
+	buffer.WriteString("# EOF\n")
+
+	return buffer.WriteTo(writer)
+}
+
+func writeHistograms(buffer *bytes.Buffer, name string, help string, histograms map[metricLabels]*histogram) {
+	fmt.Fprintf(buffer, "# TYPE %v histogram\n# HELP %v %v\n", name, name, help)
+	for _, labels := range sortedLabels(histograms) {
+		histogram := histograms[labels]
+		for i, bound := range histogramBuckets {
+			fmt.Fprintf(buffer, "%v_bucket{%v,le=\"%v\"} %v\n", name, labels.String(), strconv.FormatFloat(bound, 'g', -1, 64), histogram.buckets[i])
+		}
+		fmt.Fprintf(buffer, "%v_bucket{%v,le=\"+Inf\"} %v\n", name, labels.String(), histogram.count)
+		fmt.Fprintf(buffer, "%v_sum{%v} %v\n", name, labels.String(), strconv.FormatFloat(histogram.sum, 'g', -1, 64))
+		fmt.Fprintf(buffer, "%v_count{%v} %v\n", name, labels.String(), histogram.count)
+	}
+}
+
+func (labels metricLabels) String() string {
+	return fmt.Sprintf("name=\"%v\",type=\"%v\"", escapeLabel(labels.name), escapeLabel(labels.instanceType))
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(value)
+}
+
+// map keys in stable order
+func sortedLabels(values interface{}) []metricLabels {
+	labels := []metricLabels{}
+
+	switch values := values.(type) {
+	case map[metricLabels]uint64:
+		for key := range values {
+			labels = append(labels, key)
+		}
+	case map[metricLabels]map[State]int:
+		for key := range values {
+			labels = append(labels, key)
+		}
+	case map[metricLabels]*histogram:
+		for key := range values {
+			labels = append(labels, key)
+		}
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].name != labels[j].name {
+			return labels[i].name < labels[j].name
+		}
+		return labels[i].instanceType < labels[j].instanceType
+	})
+
+	return labels
+}
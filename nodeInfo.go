@@ -0,0 +1,53 @@
+package context
+
+import (
+	"fmt"
+)
+
+// NodeInfo describes one context of the tree.
+type NodeInfo struct {
+	// Unique context number in the tree. Contexts are numbered in creation order.
+	ID uint64 `json:"id"`
+
+	// Name of the node (see [NamedInstance])
+	Name string `json:"name"`
+
+	// Names of the node and its parents, starting from the root node, separated by slash. If node has several parents, the path goes through the first one.
+	// Path is set when context is created and does not change if context is moved to other parent.
+	Path string `json:"path"`
+
+	// Type of the node instance
+	Type string `json:"type"`
+}
+
+// module wraps some user instances (root, group members) with own ones
+type wrappedInstance interface {
+	unwrap() interface{}
+}
+
+func unwrap(instance ContextedInstance) interface{} {
+	if wrapped, ok := instance.(wrappedInstance); ok {
+		return wrapped.unwrap()
+	}
+	return instance
+}
+
+func nameOf(instance interface{}) string {
+	if named, ok := instance.(NamedInstance); ok {
+		return named.Name()
+	}
+	return typeOf(instance)
+}
+
+func typeOf(instance interface{}) string {
+	return fmt.Sprintf("%T", instance)
+}
+
+func (current *context) info() NodeInfo {
+	return NodeInfo{
+		ID:   current.id,
+		Name: current.name,
+		Path: current.path,
+		Type: typeOf(unwrap(current.instance)),
+	}
+}
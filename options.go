@@ -0,0 +1,111 @@
+package context
+
+// Option changes the behaviour of a new context. Options are passed to NewContextFor(...).
+type Option func(options *options)
+
+type options struct {
+	readiness    bool
+	dependencies []ChildContext
+	phase        string
+	phases       []string
+	policy       ShutdownPolicy
+	orphans      OrphanPolicy
+	reaper       ContextedInstance
+	onAdopted    func(orphan ChildContext, adopter ChildContext)
+	observers    []observer
+	logger       Logger
+}
+
+// ShutdownPolicy defines how children of the node are closed (see [WithShutdownPolicy]).
+type ShutdownPolicy int
+
+const (
+	// all children (of the current shutdown phase) are freezed at once in reverse creation order, their Go methods drain concurrently
+	ParallelShutdown ShutdownPolicy = 0
+	// children are freezed one by one in reverse creation order, next one only when previous is finished (its Go method returned)
+	SequentialShutdown ShutdownPolicy = 1
+)
+
+func newOptions(list []Option) *options {
+	options := &options{}
+	for _, option := range list {
+		option(options)
+	}
+	return options
+}
+
+// WithReadiness means that the node reports its readiness itself with [Context.Ready] call.
+//
+// Until then, children (and dependants) of this node are not started. Without this option, node is ready as soon as it starts.
+func WithReadiness() Option {
+	return func(options *options) {
+		options.readiness = true
+	}
+}
+
+// DependsOn starts the new node only after all dependencies are ready.
+//
+// Every dependency becomes one more parent of the new node, so it also waits for the node on closing (and closing of any dependency closes the node).
+// If the instance is already in the tree, its own descendants could not become its dependencies ([CycleError] is returned).
+func DependsOn(dependencies ...ChildContext) Option {
+	return func(options *options) {
+		options.dependencies = append(options.dependencies, dependencies...)
+	}
+}
+
+// WithShutdownPhases sets the order in which children of the node are closed. Children are assigned to phases with [InPhase] option.
+//
+// Children without phase are closed first, then phases in specified order. Next phase starts only after all children of previous phases are finished.
+// Inside one phase, children are closed in reverse creation order.
+func WithShutdownPhases(phases ...string) Option {
+	return func(options *options) {
+		options.phases = append(options.phases, phases...)
+	}
+}
+
+// InPhase assigns the node to the named shutdown phase of its parents (see [WithShutdownPhases]).
+func InPhase(phase string) Option {
+	return func(options *options) {
+		options.phase = phase
+	}
+}
+
+// WithShutdownPolicy sets how children of the node are closed. Default is [ParallelShutdown].
+//
+// With shutdown phases, the policy is applied inside every phase.
+//
+// Note: with [SequentialShutdown] the next child waits until the previous one is finished, not only until it reaches disposing state as it was first specified.
+// Disposing leaf child is still running its Go method (e.g. flushing its buffers), so waiting only for disposing started all leaf children at once. This is a deliberate change.
+func WithShutdownPolicy(policy ShutdownPolicy) Option {
+	return func(options *options) {
+		options.policy = policy
+	}
+}
+
+// WithOrphanPolicy sets what happens with a context when its last parent exits from Go() without closing. Default is [FreezeOrphan].
+//
+// This option is used only by [NewRootContext].
+func WithOrphanPolicy(policy OrphanPolicy) Option {
+	return func(options *options) {
+		options.orphans = policy
+	}
+}
+
+// WithOrphanReaper creates reaper context for instance as a child of the root context. Orphans are adopted by it (see [AdoptOrphanByReaper]).
+//
+// This option is used only by [NewRootContext].
+func WithOrphanReaper(reaper ContextedInstance) Option {
+	return func(options *options) {
+		options.orphans = AdoptOrphanByReaper
+		options.reaper = reaper
+	}
+}
+
+// OnOrphanAdopted sets callback which is called every time an orphan is adopted. Callback is called after the tree is unlocked, so it could use context methods.
+//
+// This option is used only by [NewRootContext].
+func OnOrphanAdopted(callback func(orphan ChildContext, adopter ChildContext)) Option {
+	return func(options *options) {
+		options.onAdopted = callback
+	}
+}
@@ -0,0 +1,205 @@
+package context
+
+import (
+	"sync"
+)
+
+// PoolConfig is used by [NewPool].
+type PoolConfig struct {
+	// minimal pool size, pool starts with it
+	Min int
+
+	// maximal pool size, 0 means unlimited
+	Max int
+
+	// optional shared input node. It is a child of the pool node and of every worker, so it closes before all of them.
+	Input ContextedInstance
+
+	// creates a new worker instance, index is a worker position in the pool (required)
+	NewWorker func(index int) ContextedInstance
+}
+
+// IdleWorker is an optional interface of pool worker instances. Scaling down closes idle workers first.
+//
+// IsIdle is called without tree lock, but the worker could become busy right after it, so workers still must finish their current job on closing.
+type IdleWorker interface {
+	IsIdle() bool
+}
+
+// Pool obtained from the [NewPool] function.
+//
+// All workers are children of one pool node. Scaling down closes idle workers first (see [IdleWorker]), then busy ones, the newest first, through the usual freeze/dispose path.
+// Shared input stays working even with zero workers.
+type Pool interface {
+
+	// Starts new workers or closes idle (or the last) ones to get specified pool size. Size should be in [Min, Max] range, otherwise it returns [PoolSizeOutOfRangeError].
+	Scale(size int) error
+
+	// Returns number of working workers. Workers that are closed or exited from Go() are not counted.
+	Size() int
+
+	// Close the pool, all its workers and shared input.
+	Close()
+}
+
+type pool struct {
+	config  PoolConfig
+	context *context
+	workers []*context
+	scaling sync.Mutex
+}
+
+// NewPool creates a new pool node as a child of the parent context and starts Min workers.
+func NewPool(parent ChildContext, config PoolConfig) (Pool, error) {
+	pool := &pool{
+		config:  config,
+		workers: []*context{},
+	}
+
+	poolContext, err := parent.NewContextFor(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	pool.context = contextOf(poolContext)
+
+	if config.Input != nil {
+		if _, err := poolContext.NewContextFor(config.Input); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := pool.Scale(config.Min); err != nil {
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+// Scale ...
+func (pool *pool) Scale(size int) error {
+	if size < pool.config.Min || (pool.config.Max > 0 && size > pool.config.Max) {
+		return &PoolSizeOutOfRangeError{Size: size, Min: pool.config.Min, Max: pool.config.Max}
+	}
+
+	pool.scaling.Lock()
+	defer pool.scaling.Unlock()
+
+	workers := pool.workingWorkers()
+
+	// worker instances are user code, so they are created and asked about idleness without tree lock
+	instances := []ContextedInstance{}
+	for index := len(workers); index < size; index++ {
+		instances = append(instances, pool.config.NewWorker(index))
+	}
+
+	idle := map[*context]bool{}
+	if len(workers) > size {
+		for _, worker := range workers {
+			if idleWorker, ok := worker.instance.(IdleWorker); ok && idleWorker.IsIdle() {
+				idle[worker] = true
+			}
+		}
+	}
+
+	tree := pool.context.root
+
+	tree.ready.Lock()
+	defer tree.unlock()
+
+	if err := pool.context.checkIsNotClosing(); err != nil {
+		return err
+	}
+
+	pool.removeClosedWorkers()
+
+	for _, instance := range instances {
+		worker, _ := newContextFor(pool.context, instance, newOptions(nil))
+		if input := tree.contexts[pool.config.Input]; input != nil {
+			newContextFor(worker, pool.config.Input, newOptions(nil))
+		}
+		pool.workers = append(pool.workers, worker)
+	}
+
+	for _, worker := range pool.workersToClose(len(pool.workers)-size, idle) {
+		// shared input has other parents, so it is just unlinked from the worker instead of closing
+		if input := tree.contexts[pool.config.Input]; input != nil {
+			input.unlink(worker)
+		}
+
+		worker.freezeAllChildsAndSubchilds()
+	}
+
+	return nil
+}
+
+// Size ...
+func (pool *pool) Size() int {
+	pool.context.root.ready.Lock()
+	defer pool.context.root.ready.Unlock()
+
+	pool.removeClosedWorkers()
+
+	return len(pool.workers)
+}
+
+// Close ...
+func (pool *pool) Close() {
+	pool.context.Close()
+}
+
+func (pool *pool) workingWorkers() []*context {
+	pool.context.root.ready.Lock()
+	defer pool.context.root.ready.Unlock()
+
+	pool.removeClosedWorkers()
+
+	return append([]*context{}, pool.workers...)
+}
+
+// removes count workers from the pool: idle ones first, then busy ones, the newest first
+func (pool *pool) workersToClose(count int, idle map[*context]bool) []*context {
+	closing := map[*context]bool{}
+	workers := []*context{}
+
+	for _, closeIdle := range []bool{true, false} {
+		for i := len(pool.workers) - 1; i >= 0 && len(workers) < count; i-- {
+			if worker := pool.workers[i]; idle[worker] == closeIdle && !closing[worker] {
+				closing[worker] = true
+				workers = append(workers, worker)
+			}
+		}
+	}
+
+	remaining := []*context{}
+	for _, worker := range pool.workers {
+		if !closing[worker] {
+			remaining = append(remaining, worker)
+		}
+	}
+	pool.workers = remaining
+
+	return workers
+}
+
+func (pool *pool) removeClosedWorkers() {
+	workers := []*context{}
+	for _, worker := range pool.workers {
+		if worker.state == Working || worker.state == NotStarted {
+			workers = append(workers, worker)
+		}
+	}
+	pool.workers = workers
+}
+
+func (pool *pool) Go(current Context) {
+loop:
+	for {
+		select {
+		case _, isOpened := <-current.Context():
+			if !isOpened {
+				break loop
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package context
+
+import (
+	stdcontext "context"
+	"runtime/trace"
+)
+
+// WithRuntimeTrace enables runtime/trace integration for all tree contexts.
+//
+// Every context is a trace task (nested into the task of its first parent), and its Go() method is a region of this task.
+// States (working, freezed, disposing) are subtasks of the context task, so `go tool trace` shows how long each subtree took to drain.
+// Regions could not be used for states, because they must start and end in one goroutine, and states are changed by other goroutines.
+//
+// This option is used only by [NewRootContext].
+func WithRuntimeTrace() Option {
+	return func(options *options) {
+		options.observers = append(options.observers, &runtimeTracer{
+			tasks: map[*context]*traceTask{},
+		})
+	}
+}
+
+type runtimeTracer struct {
+	tasks map[*context]*traceTask
+}
+
+type traceTask struct {
+	context stdcontext.Context
+	task    *trace.Task
+	state   *trace.Task
+}
+
+func (tracer *runtimeTracer) observe(event *event) {
+	switch event.kind {
+	case eventCreated:
+		parentContext := stdcontext.Background()
+		if parent := tracer.tasks[event.parent]; parent != nil {
+			parentContext = parent.context
+		}
+
+		taskContext, task := trace.NewTask(parentContext, "context "+event.node.path)
+		tracer.tasks[event.node] = &traceTask{
+			context: taskContext,
+			task:    task,
+		}
+		event.node.traceContext = taskContext
+
+	case eventStateChanged:
+		task := tracer.tasks[event.node]
+		if task == nil {
+			return
+		}
+
+		if task.state != nil {
+			task.state.End()
+			task.state = nil
+		}
+
+		trace.Log(task.context, "state", event.node.state.String())
+
+		if event.node.state == Finished {
+			task.task.End()
+			delete(tracer.tasks, event.node)
+			return
+		}
+
+		_, task.state = trace.NewTask(task.context, event.node.state.String())
+
+	case eventExitedEarly, eventPanicked, eventRejected, eventAdopted, eventUnlinked, eventFailed:
+		if task := tracer.tasks[event.node]; task != nil {
+			trace.Log(task.context, "event", event.String())
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package context
+
+// Scope obtained from the Context NewScope() method.
+//
+// Scope is a function-local context for request handlers and similar code:
+//
+//	scope := current.NewScope()
+//	defer scope.CloseAndWait()
+//
+// CloseAndWait() is safe to call from inside Go() method, unlike a generic Wait() (see README, common question 2).
+// The scope node has no channel back to the caller, so it never blocks waiting for the caller.
+// Children of the scope are safe too when every their send to the caller is done in select together with their own current.Context() channel:
+// CloseAndWait() closes them, so they exit instead of waiting for the blocked caller.
+type Scope interface {
+
+	// creates a new child context under the scope. If scope parent was already closing, it returns the same error as parent NewContextFor(...)
+	NewContextFor(instance ContextedInstance, options ...Option) (ChildContext, error)
+
+	// Close all scope children in reverse order and wait until they and the scope itself are finished.
+	CloseAndWait()
+}
+
+type scope struct {
+	context ChildContext
+	err     error
+}
+
+// NewScope ...
+func (current *context) NewScope() Scope {
+	scope := &scope{}
+	scope.context, scope.err = current.NewContextFor(scope)
+	return scope
+}
+
+// NewContextFor ...
+func (scope *scope) NewContextFor(instance ContextedInstance, options ...Option) (ChildContext, error) {
+	if scope.err != nil {
+		return nil, scope.err
+	}
+	return scope.context.NewContextFor(instance, options...)
+}
+
+// CloseAndWait ...
+func (scope *scope) CloseAndWait() {
+	if scope.err != nil {
+		return
+	}
+	scope.context.Close()
+	<-scope.context.StateReached(Finished)
+}
+
+func (scope *scope) Go(current Context) {
+loop:
+	for {
+		select {
+		case _, isOpened := <-current.Context():
+			if !isOpened {
+				break loop
+			}
+		}
+	}
+}
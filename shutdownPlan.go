@@ -0,0 +1,189 @@
+package context
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ShutdownPlan is a dry run of the tree shutdown (see [RootContext.ShutdownPlan]).
+//
+// Rounds contain contexts in the order they would become disposing: contexts of the next round are disposed only when all contexts of the previous rounds they wait for are finished.
+// Plan could be marshalled to JSON as is.
+type ShutdownPlan struct {
+	Rounds [][]NodeInfo `json:"rounds"`
+}
+
+// simulated copy of the tree node, so the plan does not change the tree (see shutdownNode)
+type planNode struct {
+	context *context
+	state   State
+	removed bool
+	parents map[*planNode]bool
+	childs  map[*planNode]bool
+}
+
+func (node *planNode) treeContext() *context {
+	return node.context
+}
+
+func (node *planNode) currentState() State {
+	return node.state
+}
+
+type shutdownSimulation struct {
+	nodes     map[*context]*planNode
+	disposing []*planNode
+}
+
+// ShutdownPlan simulates Close() of the current tree without changing it. Shutdown phases, sequential shutdown policies and contexts with several parents are taken into account.
+//
+// Simulation supposes that every Go method returns as soon as its context is closed. Contexts which are already disposing are in the first round.
+func (root *rootContext) ShutdownPlan() ShutdownPlan {
+	tree := root.context.root
+
+	tree.ready.Lock()
+	defer tree.ready.Unlock()
+
+	simulation := &shutdownSimulation{
+		nodes: map[*context]*planNode{},
+	}
+
+	rootNode := simulation.copy(root.context)
+
+	for _, node := range simulation.sorted(simulation.nodes) {
+		if node.state == Disposing {
+			simulation.disposing = append(simulation.disposing, node)
+		}
+	}
+
+	// already freezed nodes without children wait only for their spawned tasks, which are supposed to be finished
+	for _, node := range simulation.sorted(simulation.nodes) {
+		if node.state == Freezed {
+			simulation.freezeNext(node)
+		}
+	}
+
+	simulation.freeze(rootNode)
+
+	plan := ShutdownPlan{
+		Rounds: [][]NodeInfo{},
+	}
+
+	for len(simulation.disposing) > 0 {
+		round := simulation.disposing
+		simulation.disposing = nil
+
+		sort.Slice(round, func(i, j int) bool { return round[i].context.id < round[j].context.id })
+
+		nodes := []NodeInfo{}
+		for _, node := range round {
+			nodes = append(nodes, node.context.info())
+		}
+		plan.Rounds = append(plan.Rounds, nodes)
+
+		for _, node := range round {
+			simulation.remove(node)
+		}
+	}
+
+	return plan
+}
+
+func (simulation *shutdownSimulation) copy(current *context) *planNode {
+	if node, found := simulation.nodes[current]; found {
+		return node
+	}
+
+	node := &planNode{
+		context: current,
+		state:   current.state,
+		parents: map[*planNode]bool{},
+		childs:  map[*planNode]bool{},
+	}
+	simulation.nodes[current] = node
+
+	for child := range current.childs {
+		childNode := simulation.copy(child)
+		node.childs[childNode] = true
+		childNode.parents[node] = true
+	}
+
+	return node
+}
+
+func (simulation *shutdownSimulation) sorted(nodes map[*context]*planNode) []*planNode {
+	sorted := make([]*planNode, 0, len(nodes))
+	for _, node := range nodes {
+		sorted = append(sorted, node)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].context.id < sorted[j].context.id })
+
+	return sorted
+}
+
+// state transitions below follow context.freezeAllChildsAndSubchilds, freezeNextChilds, dispose and remove, children order is chosen by the shared nextChildsToFreeze
+func (simulation *shutdownSimulation) freeze(node *planNode) {
+	if node.state == Working || node.state == NotStarted {
+		node.state = Freezed
+		simulation.freezeNext(node)
+	}
+}
+
+// spawned tasks are supposed to be finished
+func (simulation *shutdownSimulation) freezeNext(node *planNode) {
+	if node.state != Freezed {
+		return
+	}
+
+	if len(node.childs) == 0 {
+		simulation.dispose(node)
+		return
+	}
+
+	childs := []shutdownNode{}
+	for child := range node.childs {
+		childs = append(childs, child)
+	}
+
+	for _, child := range nextChildsToFreeze(node.context, childs) {
+		simulation.freeze(child.(*planNode))
+	}
+}
+
+func (simulation *shutdownSimulation) dispose(node *planNode) {
+	node.state = Disposing
+	simulation.disposing = append(simulation.disposing, node)
+
+	if !node.context.isStarted {
+		simulation.remove(node)
+	}
+}
+
+func (simulation *shutdownSimulation) remove(node *planNode) {
+	if node.removed {
+		return
+	}
+	node.removed = true
+
+	for parent := range node.parents {
+		delete(parent.childs, node)
+		simulation.freezeNext(parent)
+	}
+}
+
+// String returns rounds one per line, contexts are identified by their paths.
+func (plan ShutdownPlan) String() string {
+	builder := &strings.Builder{}
+
+	for i, round := range plan.Rounds {
+		paths := []string{}
+		for _, node := range round {
+			paths = append(paths, node.Path)
+		}
+		fmt.Fprintf(builder, "round %v: %v\n", i+1, strings.Join(paths, ", "))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,75 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ShutdownReport is the critical path of the tree shutdown: the chain of contexts which determined the total shutdown time.
+//
+// It starts from the root, every next step is the child which was removed last and so allowed its parent to start disposing.
+type ShutdownReport struct {
+	// from the root freeze until the root is finished
+	Total time.Duration
+	Steps []ShutdownStep
+}
+
+// ShutdownStep is one context of the shutdown critical path.
+type ShutdownStep struct {
+	NodeInfo
+	// from the context freeze until its last child is removed and the context starts disposing
+	Drain time.Duration
+	// from the context disposing until its Go method (and all spawned tasks) returned
+	Exit time.Duration
+}
+
+// ShutdownReport returns critical path of the tree shutdown. Call it after Wait(), for the tree that is still closing it contains only finished steps.
+func (root *rootContext) ShutdownReport() ShutdownReport {
+	tree := root.context.root
+
+	tree.ready.Lock()
+	defer tree.ready.Unlock()
+
+	node := root.context
+
+	report := ShutdownReport{
+		Total: between(node.at[Freezed], node.at[Finished]),
+		Steps: []ShutdownStep{},
+	}
+
+	for visited := map[*context]bool{}; node != nil && !visited[node]; node = node.lastChild {
+		visited[node] = true
+
+		report.Steps = append(report.Steps, ShutdownStep{
+			NodeInfo: node.info(),
+			Drain:    between(node.at[Freezed], node.at[Disposing]),
+			Exit:     between(node.at[Disposing], node.at[Finished]),
+		})
+	}
+
+	return report
+}
+
+func between(from time.Time, to time.Time) time.Duration {
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+	return to.Sub(from)
+}
+
+// String returns the critical path as a table, one step per line.
+func (report ShutdownReport) String() string {
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "shutdown took %v\n", report.Total)
+
+	writer := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "PATH\tDRAIN\tEXIT\n")
+	for _, step := range report.Steps {
+		fmt.Fprintf(writer, "%v\t%v\t%v\n", step.Path, step.Drain, step.Exit)
+	}
+	writer.Flush()
+
+	return builder.String()
+}
@@ -0,0 +1,108 @@
+package context
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// TreeStats returned by RootContext Stats() method.
+type TreeStats struct {
+	// number of contexts in every state, by state name
+	States map[string]int `json:"states"`
+
+	// number of contexts in the tree
+	Total int `json:"total"`
+
+	// number of levels of the tree, root context only is 1
+	MaxDepth int `json:"maxDepth"`
+
+	// context which is in freezed state for the longest time, nil if there is no such context
+	OldestFreezed *FreezedNode `json:"oldestFreezed"`
+}
+
+// FreezedNode is a context waiting for its children.
+type FreezedNode struct {
+	NodeInfo
+	Since      time.Time     `json:"since"`
+	FreezedFor time.Duration `json:"freezedFor"`
+}
+
+func (root *root) stats() TreeStats {
+	root.ready.Lock()
+	defer root.ready.Unlock()
+
+	stats := TreeStats{
+		States: map[string]int{},
+		Total:  len(root.contexts),
+	}
+
+	for state := NotStarted; state < Finished; state++ {
+		stats.States[state.String()] = 0
+	}
+
+	now := time.Now()
+
+	for _, node := range root.contexts {
+		stats.States[node.state.String()]++
+
+		if node.state == Freezed && (stats.OldestFreezed == nil || node.at[Freezed].Before(stats.OldestFreezed.Since)) {
+			stats.OldestFreezed = &FreezedNode{
+				NodeInfo:   node.info(),
+				Since:      node.at[Freezed],
+				FreezedFor: now.Sub(node.at[Freezed]),
+			}
+		}
+	}
+
+	if root.node.state != Finished {
+		stats.MaxDepth = root.node.depth(map[*context]int{})
+	}
+
+	return stats
+}
+
+// longest path from the node to its deepest child
+func (current *context) depth(depths map[*context]int) int {
+	if depth, found := depths[current]; found {
+		return depth
+	}
+
+	// node in progress is memoized before its children, so an unexpected cycle could not recurse forever (see subtree() in health.go)
+	depths[current] = 1
+
+	depth := 1
+	for child := range current.childs {
+		if childDepth := child.depth(depths) + 1; childDepth > depth {
+			depth = childDepth
+		}
+	}
+
+	depths[current] = depth
+
+	return depth
+}
+
+var expvarPublishing sync.Mutex
+
+// PublishExpvar publishes root context statistics (see [TreeStats]) as expvar variable with specified name.
+// Every root context should use its own name, otherwise it returns [ExpvarIsAlreadyPublishedError].
+func PublishExpvar(name string, root RootContext) error {
+	rootContext, ok := root.(*rootContext)
+	if !ok {
+		return &ForeignContextError{}
+	}
+
+	expvarPublishing.Lock()
+	defer expvarPublishing.Unlock()
+
+	if expvar.Get(name) != nil {
+		return &ExpvarIsAlreadyPublishedError{Name: name}
+	}
+
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return rootContext.context.root.stats()
+	}))
+
+	return nil
+}
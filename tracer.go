@@ -0,0 +1,182 @@
+package context
+
+import (
+	"sync"
+)
+
+// Tracer starts a span for every tree context, so contexts could be exported to any tracing system (an OpenTelemetry adapter is a few lines of your own code).
+//
+// Tracer and its spans are called under the tree lock: they must be fast and must not call any tree methods.
+type Tracer interface {
+	// StartSpan is called when the context is created. Parents contains span of the parent which created this context.
+	StartSpan(info NodeInfo, parents []Span) Span
+}
+
+// Span traces one context from its creation until it is finished.
+type Span interface {
+	// Link is called when one more parent is linked to the context (DependsOn, Move, orphan adoption), so spans mirror the tree DAG.
+	Link(parent Span)
+	// Event is called on context states changes (working, freezed, disposing) and on early exits, panics, errors, adoptions and unlinks.
+	Event(name string)
+	// End is called when the context is finished and removed from the tree.
+	End()
+}
+
+// WithTracer reports lifecycle of all tree contexts as spans of tracer (see [Tracer]).
+//
+// This option is used only by [NewRootContext].
+func WithTracer(tracer Tracer) Option {
+	return func(options *options) {
+		options.observers = append(options.observers, &tracerObserver{
+			tracer: tracer,
+			spans:  map[*context]*tracedSpan{},
+		})
+	}
+}
+
+type tracerObserver struct {
+	tracer Tracer
+	spans  map[*context]*tracedSpan
+}
+
+type tracedSpan struct {
+	span    Span
+	parents map[*context]bool
+}
+
+func (observer *tracerObserver) observe(event *event) {
+	switch event.kind {
+	case eventCreated:
+		parents := []Span{}
+		if parent := observer.spans[event.parent]; parent != nil {
+			parents = append(parents, parent.span)
+		}
+
+		observer.spans[event.node] = &tracedSpan{
+			span:    observer.tracer.StartSpan(event.node.info(), parents),
+			parents: map[*context]bool{event.parent: true},
+		}
+
+	case eventLinked:
+		span, parent := observer.spans[event.node], observer.spans[event.parent]
+		if span == nil || parent == nil || span.parents[event.parent] {
+			return
+		}
+		span.parents[event.parent] = true
+		span.span.Link(parent.span)
+
+	case eventUnlinked:
+		if span := observer.spans[event.node]; span != nil {
+			delete(span.parents, event.parent)
+			span.span.Event(event.String())
+		}
+
+	case eventStateChanged:
+		span := observer.spans[event.node]
+		if span == nil {
+			return
+		}
+
+		if event.node.state == Finished {
+			span.span.End()
+			delete(observer.spans, event.node)
+			return
+		}
+
+		span.span.Event(event.String())
+
+	case eventExitedEarly, eventPanicked, eventAdopted, eventFailed:
+		if span := observer.spans[event.node]; span != nil {
+			span.span.Event(event.String())
+		}
+	}
+}
+
+// RecordingTracer is an in-memory [Tracer] for tests, it keeps all spans.
+type RecordingTracer struct {
+	spans []*recordingSpan
+	ready sync.Mutex
+}
+
+// RecordedSpan is a snapshot of the span recorded by [RecordingTracer].
+type RecordedSpan struct {
+	Info    NodeInfo
+	Parents []NodeInfo
+	Events  []string
+	Ended   bool
+}
+
+type recordingSpan struct {
+	tracer   *RecordingTracer
+	recorded RecordedSpan
+}
+
+// NewRecordingTracer creates empty recording tracer.
+func NewRecordingTracer() *RecordingTracer {
+	return &RecordingTracer{}
+}
+
+// StartSpan records a new span.
+func (tracer *RecordingTracer) StartSpan(info NodeInfo, parents []Span) Span {
+	tracer.ready.Lock()
+	defer tracer.ready.Unlock()
+
+	span := &recordingSpan{
+		tracer: tracer,
+		recorded: RecordedSpan{
+			Info:    info,
+			Parents: []NodeInfo{},
+			Events:  []string{},
+		},
+	}
+
+	for _, parent := range parents {
+		span.link(parent)
+	}
+
+	tracer.spans = append(tracer.spans, span)
+
+	return span
+}
+
+// Spans returns snapshots of all recorded spans in order of their start.
+func (tracer *RecordingTracer) Spans() []RecordedSpan {
+	tracer.ready.Lock()
+	defer tracer.ready.Unlock()
+
+	spans := make([]RecordedSpan, len(tracer.spans))
+	for i, span := range tracer.spans {
+		spans[i] = span.recorded
+		spans[i].Parents = append([]NodeInfo{}, span.recorded.Parents...)
+		spans[i].Events = append([]string{}, span.recorded.Events...)
+	}
+
+	return spans
+}
+
+func (span *recordingSpan) link(parent Span) {
+	if parent, ok := parent.(*recordingSpan); ok {
+		span.recorded.Parents = append(span.recorded.Parents, parent.recorded.Info)
+	}
+}
+
+func (span *recordingSpan) Link(parent Span) {
+	span.tracer.ready.Lock()
+	defer span.tracer.ready.Unlock()
+
+	span.link(parent)
+}
+
+func (span *recordingSpan) Event(name string) {
+	span.tracer.ready.Lock()
+	defer span.tracer.ready.Unlock()
+
+	span.recorded.Events = append(span.recorded.Events, name)
+}
+
+func (span *recordingSpan) End() {
+	span.tracer.ready.Lock()
+	defer span.tracer.ready.Unlock()
+
+	span.recorded.Ended = true
+}